@@ -0,0 +1,109 @@
+package cron
+
+import (
+	"time"
+)
+
+// BlackoutPolicy selects what happens to the runs that are due during a
+// blackout.
+type BlackoutPolicy int
+
+const (
+	SkipRun  BlackoutPolicy = iota // The run is dropped, and the entry follows its schedule
+	DeferRun                       // The run happens at the end of the blackout
+)
+
+// Blackout is a set of times, given by a Calendar, during which the Cron does
+// not start any jobs.
+type Blackout struct {
+	Calendar Calendar
+	Policy   BlackoutPolicy
+}
+
+// BlackoutEvent reports a run of an entry's job that was due during a blackout,
+// and was skipped or deferred.
+type BlackoutEvent struct {
+	// The entry whose job was due.
+	Entry EntryID
+
+	// The time the run was due.
+	Time time.Time
+
+	// Whether the run was skipped or deferred.
+	Policy BlackoutPolicy
+
+	// The end of the blackout. Deferred runs happen at this time, unless
+	// another blackout has begun by then.
+	Until time.Time
+}
+
+// AddBlackout keeps the Cron from starting any jobs at the times excluded by the
+// calendar. Runs that are due during the blackout are skipped or deferred,
+// according to policy. When blackouts with both policies overlap, runs are
+// skipped. Several runs of an entry deferred by the same blackout are merged
+// into one.
+//
+// One-off blackouts may be given by a MemoryCalendar, and recurring ones with
+// Windows:
+//
+//	sundays, _ := cron.Parse("0 0 3 * * SUN")
+//	c.AddBlackout(cron.Windows(sundays, time.Hour), cron.SkipRun)
+func (c *Cron) AddBlackout(cal Calendar, policy BlackoutPolicy) {
+	b := Blackout{cal, policy}
+	if c.running {
+		c.blackout <- b
+		return
+	}
+	c.blackouts = append(c.blackouts, b)
+}
+
+// blackedOut reports whether t falls within a blackout. If it does, it also
+// returns the policy to apply and the end of the blackout.
+func (c *Cron) blackedOut(t time.Time) (bool, BlackoutPolicy, time.Time) {
+	var (
+		found  bool
+		policy BlackoutPolicy
+		until  time.Time
+	)
+	for _, b := range c.blackouts {
+		excluded, end := b.Calendar.Excludes(t)
+		if !excluded {
+			continue
+		}
+		if !found || b.Policy == SkipRun {
+			policy = b.Policy
+		}
+		if end.After(until) {
+			until = end
+		}
+		found = true
+	}
+	return found, policy, until
+}
+
+// holdBack checks whether the entry's run due at t, triggered by the given
+// upstream executions (if any), falls within a blackout. If it does, the entry
+// is rescheduled according to the policy, the event is reported, and holdBack
+// returns true.
+func (c *Cron) holdBack(e *Entry, t, now time.Time, triggeredBy []Execution) bool {
+	blackedOut, policy, until := c.blackedOut(t)
+	if !blackedOut {
+		return false
+	}
+	switch policy {
+	case SkipRun:
+		e.deferredBy = nil
+		e.Next = e.next(now)
+	case DeferRun:
+		e.deferredBy = triggeredBy
+		e.Next = until
+		if !e.End.IsZero() && until.After(e.End) {
+			// The entry retires before the blackout ends.
+			e.Next = time.Time{}
+		}
+	}
+	if c.OnBlackout != nil {
+		c.OnBlackout(BlackoutEvent{Entry: e.ID, Time: t, Policy: policy, Until: until})
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+package cron
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlackedOut(t *testing.T) {
+	sundays, _ := Parse("0 0 3 * * SUN")
+	freeze := NewCalendar()
+	freeze.ExcludePeriod(getTime("Sun Jul 8 03:30 2012"), getTime("Sun Jul 8 05:00 2012"))
+
+	cron := New()
+	cron.AddBlackout(Windows(sundays, time.Hour), DeferRun)
+	cron.AddBlackout(freeze, SkipRun)
+
+	tests := []struct {
+		time       string
+		blackedOut bool
+		policy     BlackoutPolicy
+		until      string
+	}{
+		{"Sun Jul 8 02:59 2012", false, 0, ""},
+		{"Sun Jul 8 03:00 2012", true, DeferRun, "Sun Jul 8 04:00 2012"},
+		{"Sun Jul 8 03:30 2012", true, SkipRun, "Sun Jul 8 05:00 2012"},
+		{"Sun Jul 8 04:30 2012", true, SkipRun, "Sun Jul 8 05:00 2012"},
+		{"Sun Jul 8 05:00 2012", false, 0, ""},
+		{"Sun Jul 15 03:30 2012", true, DeferRun, "Sun Jul 15 04:00 2012"},
+	}
+
+	for _, c := range tests {
+		blackedOut, policy, until := cron.blackedOut(getTime(c.time))
+		if blackedOut != c.blackedOut {
+			t.Errorf("%s: expected blacked out %v", c.time, c.blackedOut)
+			continue
+		}
+		if c.blackedOut && (policy != c.policy || !until.Equal(getTime(c.until))) {
+			t.Errorf("%s: (expected) %v %v != %v %v (actual)", c.time, c.policy, c.until, policy, until)
+		}
+	}
+}
+
+// Test that runs due during a blackout are skipped, and reported.
+func TestBlackoutSkip(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	start := time.Now()
+	blackout := NewCalendar()
+	blackout.ExcludePeriod(start, start.Add(1500*time.Millisecond))
+
+	var (
+		mu     sync.Mutex
+		events []BlackoutEvent
+		ran    time.Time
+	)
+	cron := New()
+	cron.AddBlackout(blackout, SkipRun)
+	cron.OnBlackout = func(ev BlackoutEvent) {
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	}
+	cron.AddFunc("* * * * * ?", func() {
+		mu.Lock()
+		if ran.IsZero() {
+			ran = time.Now()
+			wg.Done()
+		}
+		mu.Unlock()
+	}, WithRunOnStart(true))
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(3 * OneSecond):
+		t.Fatal("expected job runs after the blackout")
+	case <-wait(wg):
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if ran.Before(start.Add(1500 * time.Millisecond)) {
+		t.Errorf("expected job does not run before the end of the blackout, ran at %v", ran)
+	}
+	if len(events) < 2 {
+		t.Fatalf("expected the run on start and at least one tick skipped, got %+v", events)
+	}
+	for _, ev := range events {
+		if ev.Policy != SkipRun || ev.Entry != 1 {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	}
+}
+
+// Test that runs due during a blackout are deferred to its end, and reported.
+func TestBlackoutDefer(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	end := time.Now().Add(500 * time.Millisecond)
+	blackout := NewCalendar()
+	blackout.ExcludePeriod(end.Add(-time.Hour), end)
+
+	var events []BlackoutEvent
+	cron := New()
+	cron.AddBlackout(blackout, DeferRun)
+	cron.OnBlackout = func(ev BlackoutEvent) { events = append(events, ev) }
+	cron.AddFunc("@every 1h", func() { wg.Done() }, WithRunOnStart(true))
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job runs at the end of the blackout")
+	case <-wait(wg):
+	}
+
+	entries := cron.Entries()
+	if !entries[0].Prev.Equal(end) {
+		t.Errorf("expected job runs at %v, ran at %v", end, entries[0].Prev)
+	}
+	if len(events) != 1 || events[0].Policy != DeferRun || !events[0].Until.Equal(end) {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
+
+// Test that runs triggered by dependencies during a blackout are deferred,
+// keeping the executions that triggered them.
+func TestBlackoutDeferDependent(t *testing.T) {
+	now := time.Now()
+	blackout := NewCalendar()
+	blackout.ExcludePeriod(now.Add(-time.Hour), now.Add(time.Hour))
+
+	cron := New()
+	cron.AddBlackout(blackout, DeferRun)
+	extract, _ := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}))
+	transform, _ := cron.AddDependent(FuncJob(func() {}), OnSuccess, extract)
+
+	ex := Execution{Entry: extract, Run: 1, Time: now, Success: true}
+	cron.trigger(ex, now)
+
+	e := cron.entry(transform)
+	if e.Runs != 0 || !e.Next.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected run deferred to the end of the blackout, got runs %d, next %v", e.Runs, e.Next)
+	}
+	if len(e.deferredBy) != 1 || e.deferredBy[0] != ex {
+		t.Errorf("expected deferred run triggered by %v, got %v", ex, e.deferredBy)
+	}
+}
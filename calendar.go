@@ -0,0 +1,264 @@
+package cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Calendar is a set of excluded times, such as public holidays or company
+// shutdown days.
+type Calendar interface {
+	// Excludes reports whether the given time is excluded. If it is, it also
+	// returns the end of the exclusion: the earliest later time that is not
+	// excluded.
+	Excludes(time.Time) (bool, time.Time)
+}
+
+// ExcludeSchedule wraps a Schedule so that it skips over the times excluded by
+// a Calendar.
+type ExcludeSchedule struct {
+	Schedule Schedule
+	Calendar Calendar
+}
+
+// Exclude returns a Schedule that activates when the given schedule does,
+// except at the times excluded by the calendar.
+func Exclude(schedule Schedule, calendar Calendar) ExcludeSchedule {
+	return ExcludeSchedule{schedule, calendar}
+}
+
+// maxExclusions is the number of excluded activations that ExcludeSchedule
+// skips in search of the next activation, before giving up.
+const maxExclusions = 10000
+
+// Next returns the next activation time of the wrapped schedule, later than the
+// given time, that is not excluded by the calendar. Excluded activations are
+// skipped by resuming the search at the end of the exclusion, which may itself
+// be an activation; intervals follow on from it. If no time is found after
+// skipping maxExclusions exclusions, it returns the zero time.
+func (s ExcludeSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < maxExclusions; i++ {
+		next := s.Schedule.Next(t)
+		if next.IsZero() {
+			return next
+		}
+		excluded, until := s.Calendar.Excludes(next)
+		if !excluded {
+			return next
+		}
+
+		t = next
+		if until.After(next) {
+			t = resumeFrom(s.Schedule, until)
+		}
+	}
+	return time.Time{}
+}
+
+// Period is a span of time, from Start (inclusive) to End (exclusive).
+type Period struct {
+	Start, End time.Time
+}
+
+// MemoryCalendar is a Calendar of excluded dates and periods, kept in memory.
+type MemoryCalendar struct {
+	// Location is the time zone in which dates are matched. If nil, dates are
+	// matched in the location of the time being checked.
+	Location *time.Location
+
+	dates   map[calendarDate]bool
+	periods []Period
+}
+
+// calendarDate is a date without a time zone.
+type calendarDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// NewCalendar returns an empty MemoryCalendar.
+func NewCalendar() *MemoryCalendar {
+	return &MemoryCalendar{
+		dates: make(map[calendarDate]bool),
+	}
+}
+
+// ExcludeDate excludes the whole of the given date.
+func (c *MemoryCalendar) ExcludeDate(year int, month time.Month, day int) {
+	// Normalize dates such as February 30th, like time.Date does.
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	c.dates[calendarDate{t.Year(), t.Month(), t.Day()}] = true
+}
+
+// ExcludePeriod excludes the times from start (inclusive) to end (exclusive).
+func (c *MemoryCalendar) ExcludePeriod(start, end time.Time) {
+	if !end.After(start) {
+		return
+	}
+	c.periods = append(c.periods, Period{start, end})
+	sort.Slice(c.periods, func(i, j int) bool {
+		return c.periods[i].Start.Before(c.periods[j].Start)
+	})
+}
+
+// Excludes reports whether t falls on an excluded date or within an excluded
+// period. Adjacent and overlapping exclusions are merged, so the returned end
+// of the exclusion is never itself excluded.
+func (c *MemoryCalendar) Excludes(t time.Time) (bool, time.Time) {
+	until := t
+	for {
+		end := c.exclusionEnd(until)
+		if !end.After(until) {
+			break
+		}
+		until = end
+	}
+	return until.After(t), until
+}
+
+// exclusionEnd returns the end of the latest-ending exclusion containing t, or t
+// if there is none.
+func (c *MemoryCalendar) exclusionEnd(t time.Time) time.Time {
+	end := t
+	d := t
+	if c.Location != nil {
+		d = t.In(c.Location)
+	}
+	if c.dates[calendarDate{d.Year(), d.Month(), d.Day()}] {
+		end = time.Date(d.Year(), d.Month(), d.Day()+1, 0, 0, 0, 0, d.Location())
+	}
+	for _, p := range c.periods {
+		if p.Start.After(t) {
+			break
+		}
+		if p.End.After(end) {
+			end = p.End
+		}
+	}
+	return end
+}
+
+// WindowCalendar is a Calendar that excludes a window of time starting at each
+// activation of a schedule, such as a weekly maintenance window.
+type WindowCalendar struct {
+	Schedule Schedule
+	Duration time.Duration
+}
+
+// Windows returns a Calendar that excludes the times from each activation of
+// the schedule until the duration has passed.
+func Windows(schedule Schedule, d time.Duration) WindowCalendar {
+	return WindowCalendar{schedule, d}
+}
+
+// Excludes reports whether t falls within one of the windows. Overlapping
+// windows are merged, up to maxExclusions of them.
+func (c WindowCalendar) Excludes(t time.Time) (bool, time.Time) {
+	// The earliest window containing t is the first to start after t-Duration,
+	// if it starts no later than t.
+	start := c.Schedule.Next(t.Add(-c.Duration))
+	if start.IsZero() || start.After(t) || c.Duration <= 0 {
+		return false, t
+	}
+	until := start.Add(c.Duration)
+	for i := 0; i < maxExclusions; i++ {
+		start = c.Schedule.Next(start)
+		if start.IsZero() || start.After(until) {
+			break
+		}
+		if end := start.Add(c.Duration); end.After(until) {
+			until = end
+		}
+	}
+	return true, until
+}
+
+// LoadCalendar reads a MemoryCalendar from the named file. See ReadCalendar
+// for the file format.
+func LoadCalendar(name string) (*MemoryCalendar, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCalendar(f)
+}
+
+// ReadCalendar reads a MemoryCalendar with one exclusion per line, in one of
+// the forms:
+//
+//	2026-12-25                                 # a date
+//	2026-12-24 2026-12-31                      # the dates in between, inclusive
+//	2026-07-01T03:00:00Z 2026-07-01T04:00:00Z  # a period, in RFC 3339 format
+//
+// Blank lines, and everything following a "#", are ignored.
+func ReadCalendar(r io.Reader) (*MemoryCalendar, error) {
+	c := NewCalendar()
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if err := c.parseExclusion(fields); err != nil {
+			return nil, fmt.Errorf("Failed to parse calendar line %d: %s", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseExclusion adds the exclusion given by the fields of a calendar line.
+func (c *MemoryCalendar) parseExclusion(fields []string) error {
+	const date = "2006-01-02"
+	switch len(fields) {
+	case 1:
+		d, err := time.Parse(date, fields[0])
+		if err != nil {
+			return err
+		}
+		c.ExcludeDate(d.Date())
+		return nil
+
+	case 2:
+		if first, err := time.Parse(date, fields[0]); err == nil {
+			last, err := time.Parse(date, fields[1])
+			if err != nil {
+				return err
+			}
+			if last.Before(first) {
+				return fmt.Errorf("Last date (%s) before first date (%s)", fields[1], fields[0])
+			}
+			for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+				c.ExcludeDate(d.Date())
+			}
+			return nil
+		}
+		start, err := time.Parse(time.RFC3339, fields[0])
+		if err != nil {
+			return err
+		}
+		end, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return err
+		}
+		if !end.After(start) {
+			return fmt.Errorf("End of period (%s) not after start (%s)", fields[1], fields[0])
+		}
+		c.ExcludePeriod(start, end)
+		return nil
+	}
+	return fmt.Errorf("Expected 1 or 2 fields, found %d", len(fields))
+}
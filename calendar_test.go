@@ -0,0 +1,203 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryCalendarExcludes(t *testing.T) {
+	cal := NewCalendar()
+	cal.ExcludeDate(2012, time.July, 4)
+	cal.ExcludeDate(2012, time.July, 5)
+	cal.ExcludeDate(2012, time.February, 30) // March 1st
+	cal.ExcludePeriod(getTime("Mon Jul 9 09:00 2012"), getTime("Mon Jul 9 11:00 2012"))
+	cal.ExcludePeriod(getTime("Mon Jul 9 10:30 2012"), getTime("Mon Jul 9 12:00 2012"))
+	cal.ExcludePeriod(getTime("Mon Jul 9 23:00 2012"), getTime("Tue Jul 10 00:00 2012"))
+
+	tests := []struct {
+		time     string
+		excluded bool
+		until    string
+	}{
+		{"Tue Jul 3 23:59:59 2012", false, ""},
+		{"Wed Jul 4 00:00 2012", true, "Fri Jul 6 00:00 2012"},
+		{"Thu Jul 5 18:00 2012", true, "Fri Jul 6 00:00 2012"},
+		{"Fri Jul 6 00:00 2012", false, ""},
+		{"Thu Mar 1 12:00 2012", true, "Fri Mar 2 00:00 2012"},
+
+		// Overlapping periods are merged.
+		{"Mon Jul 9 08:59:59 2012", false, ""},
+		{"Mon Jul 9 09:00 2012", true, "Mon Jul 9 12:00 2012"},
+		{"Mon Jul 9 11:30 2012", true, "Mon Jul 9 12:00 2012"},
+		{"Mon Jul 9 12:00 2012", false, ""},
+		{"Mon Jul 9 23:30 2012", true, "Tue Jul 10 00:00 2012"},
+	}
+
+	for _, c := range tests {
+		excluded, until := cal.Excludes(getTime(c.time))
+		if excluded != c.excluded || excluded && !until.Equal(getTime(c.until)) {
+			t.Errorf("%s: (expected) %v until %s != %v until %v (actual)", c.time, c.excluded, c.until, excluded, until)
+		}
+	}
+}
+
+func TestMemoryCalendarLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal := NewCalendar()
+	cal.Location = tokyo
+	cal.ExcludeDate(2012, time.July, 4)
+
+	// 2012-07-03T16:00:00Z is midnight on July 4th in Tokyo.
+	excluded, until := cal.Excludes(time.Date(2012, time.July, 3, 16, 0, 0, 0, time.UTC))
+	if !excluded || !until.Equal(time.Date(2012, time.July, 4, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected excluded until midnight in Tokyo, got %v until %v", excluded, until)
+	}
+	if excluded, _ := cal.Excludes(time.Date(2012, time.July, 4, 15, 0, 0, 0, time.UTC)); excluded {
+		t.Error("expected July 5th in Tokyo is not excluded")
+	}
+}
+
+func TestExcludeScheduleNext(t *testing.T) {
+	cal := NewCalendar()
+	cal.ExcludeDate(2012, time.July, 4)
+	cal.ExcludeDate(2012, time.December, 25)
+	cal.ExcludeDate(2012, time.December, 26)
+	cal.ExcludePeriod(getTime("Mon Jul 9 09:00 2012"), getTime("Mon Jul 9 11:00 2012"))
+
+	weekdays, _ := Parse("0 0 9 * * 1-5")
+	hourly, _ := Parse("@hourly")
+	tests := []struct {
+		time     string
+		schedule Schedule
+		expected string
+	}{
+		// Weekdays at 9am, except holidays
+		{"Mon Jul 2 12:00 2012", weekdays, "Tue Jul 3 09:00 2012"},
+		{"Tue Jul 3 12:00 2012", weekdays, "Thu Jul 5 09:00 2012"},
+		{"Mon Dec 24 12:00 2012", weekdays, "Thu Dec 27 09:00 2012"},
+
+		// Hourly, resuming at the end of an excluded period
+		{"Mon Jul 9 08:30 2012", hourly, "Mon Jul 9 11:00 2012"},
+		{"Tue Jul 3 22:30 2012", hourly, "Tue Jul 3 23:00 2012"},
+		{"Tue Jul 3 23:00 2012", hourly, "Thu Jul 5 00:00 2012"},
+
+		// Intervals, following on from the end of an excluded period
+		{"Tue Jul 3 23:55 2012", Every(10 * time.Minute), "Thu Jul 5 00:10 2012"},
+		{"Tue Jul 3 23:30 2012", Every(time.Hour), "Thu Jul 5 01:00 2012"},
+		{"Thu Jul 5 01:00 2012", Every(time.Hour), "Thu Jul 5 02:00 2012"},
+		{"Mon Jul 9 08:30 2012", Every(time.Hour), "Mon Jul 9 12:00 2012"},
+	}
+
+	for _, c := range tests {
+		actual := Exclude(c.schedule, cal).Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %v: (expected) %v != %v (actual)", c.time, c.schedule, expected, actual)
+		}
+	}
+}
+
+// excludeAll is a Calendar that excludes every time, one second at a time.
+type excludeAll struct{}
+
+func (excludeAll) Excludes(t time.Time) (bool, time.Time) {
+	return true, t.Add(time.Second)
+}
+
+// Test that Next skips long exclusions at once, and gives up on a calendar that
+// excludes every activation.
+func TestExcludeScheduleLimit(t *testing.T) {
+	from := time.Date(2012, time.July, 1, 0, 0, 0, 0, time.UTC)
+	cal := NewCalendar()
+	cal.ExcludePeriod(from, from.AddDate(100, 0, 0))
+	if actual, expected := Exclude(Every(time.Hour), cal).Next(from), from.AddDate(100, 0, 0).Add(time.Hour); !actual.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+	if actual := Exclude(Every(time.Hour), excludeAll{}).Next(from); !actual.IsZero() {
+		t.Errorf("expected the zero time, got %v", actual)
+	}
+}
+
+func TestWindowCalendarExcludes(t *testing.T) {
+	sundays, _ := Parse("0 0 3 * * SUN")
+	twice, _ := Parse("0 0,30 3 * * *")
+	tests := []struct {
+		calendar Calendar
+		time     string
+		excluded bool
+		until    string
+	}{
+		// Sundays 3am-4am
+		{Windows(sundays, time.Hour), "Sun Jul 8 02:59:59 2012", false, ""},
+		{Windows(sundays, time.Hour), "Sun Jul 8 03:00 2012", true, "Sun Jul 8 04:00 2012"},
+		{Windows(sundays, time.Hour), "Sun Jul 8 03:59:59 2012", true, "Sun Jul 8 04:00 2012"},
+		{Windows(sundays, time.Hour), "Sun Jul 8 04:00 2012", false, ""},
+		{Windows(sundays, time.Hour), "Mon Jul 9 03:30 2012", false, ""},
+
+		// Overlapping and adjacent windows are merged
+		{Windows(twice, 45*time.Minute), "Sun Jul 8 03:10 2012", true, "Sun Jul 8 04:15 2012"},
+		{Windows(twice, 30*time.Minute), "Sun Jul 8 03:10 2012", true, "Sun Jul 8 04:00 2012"},
+		{Windows(twice, 10*time.Minute), "Sun Jul 8 03:10 2012", false, ""},
+	}
+
+	for _, c := range tests {
+		excluded, until := c.calendar.Excludes(getTime(c.time))
+		if excluded != c.excluded {
+			t.Errorf("%s: expected excluded %v", c.time, c.excluded)
+		}
+		if c.excluded && !until.Equal(getTime(c.until)) {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.time, getTime(c.until), until)
+		}
+	}
+}
+
+func TestLoadCalendar(t *testing.T) {
+	cal, err := LoadCalendar("testdata/holidays.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		time     time.Time
+		excluded bool
+	}{
+		{time.Date(2012, time.December, 24, 12, 0, 0, 0, time.UTC), false},
+		{time.Date(2012, time.December, 25, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2012, time.December, 26, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2012, time.December, 29, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2012, time.December, 31, 23, 59, 59, 0, time.UTC), true},
+		{time.Date(2013, time.January, 1, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2012, time.July, 10, 9, 30, 0, 0, time.UTC), true},
+		{time.Date(2012, time.July, 10, 11, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, c := range tests {
+		if excluded, _ := cal.Excludes(c.time); excluded != c.excluded {
+			t.Errorf("%v: (expected) %v != %v (actual)", c.time, c.excluded, excluded)
+		}
+	}
+}
+
+func TestReadCalendarErrors(t *testing.T) {
+	tests := []struct {
+		input, err string
+	}{
+		{"2012-13-01", "line 1"},
+		{"# comment\n2012-12-25 x", "line 2"},
+		{"2012-12-31 2012-12-25", "before first date"},
+		{"2012-07-10T11:00:00Z 2012-07-10T09:00:00Z", "not after start"},
+		{"2012-07-10T11:00:00Z", "line 1"},
+		{"2012-12-25 2012-12-26 2012-12-27", "Expected 1 or 2 fields"},
+	}
+
+	for _, c := range tests {
+		_, err := ReadCalendar(strings.NewReader(c.input))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%q => expected %v, got %v", c.input, c.err, err)
+		}
+	}
+}
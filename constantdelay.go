@@ -4,8 +4,13 @@ import "time"
 
 // ConstantDelaySchedule represents a simple recurring duty cycle, e.g. "Every 5 minutes".
 // It does not support jobs more frequent than once a second.
+//
+// Without an anchor, the schedule activates one delay after any given time. An
+// anchored schedule activates at the anchor, and at every multiple of the delay
+// before or after it, whatever time it is asked about.
 type ConstantDelaySchedule struct {
-	Delay time.Duration
+	Delay  time.Duration
+	Anchor time.Time
 }
 
 // Every returns a crontab Schedule that activates once every duration.
@@ -20,8 +25,48 @@ func Every(duration time.Duration) ConstantDelaySchedule {
 	}
 }
 
+// EveryFrom returns a crontab Schedule that activates at the anchor and once
+// every duration before and after it, as Every does. The anchor is truncated to
+// the second.
+func EveryFrom(anchor time.Time, duration time.Duration) ConstantDelaySchedule {
+	schedule := Every(duration)
+	schedule.Anchor = anchor.Add(-time.Duration(anchor.Nanosecond()))
+	return schedule
+}
+
 // Next returns the next time this should be run.
 // This rounds so that the next activation time will be on the second.
 func (schedule ConstantDelaySchedule) Next(t time.Time) time.Time {
+	if !schedule.Anchor.IsZero() {
+		return schedule.Anchor.Add(schedule.periods(t.Sub(schedule.Anchor)) + schedule.Delay).In(t.Location())
+	}
 	return t.Add(schedule.Delay - time.Duration(t.Nanosecond())*time.Nanosecond)
 }
+
+// Prev returns the latest activation of an anchored schedule before the given
+// time. An unanchored schedule has no fixed activations, so it returns the zero
+// time.
+func (schedule ConstantDelaySchedule) Prev(t time.Time) time.Time {
+	if schedule.Anchor.IsZero() {
+		return time.Time{}
+	}
+	return schedule.Anchor.Add(schedule.periods(t.Sub(schedule.Anchor) - time.Nanosecond)).In(t.Location())
+}
+
+// periods rounds the offset from the anchor down to a whole number of delays.
+func (schedule ConstantDelaySchedule) periods(d time.Duration) time.Duration {
+	n := d / schedule.Delay
+	if d%schedule.Delay < 0 {
+		n--
+	}
+	return n * schedule.Delay
+}
+
+// String returns the spec of the schedule, as in "@every 1h30m0s", or as in
+// "@every 1h30m0s from 2026-12-01T09:00:00Z" if it is anchored.
+func (schedule ConstantDelaySchedule) String() string {
+	if !schedule.Anchor.IsZero() {
+		return "@every " + schedule.Delay.String() + " from " + schedule.Anchor.Format(time.RFC3339)
+	}
+	return "@every " + schedule.Delay.String()
+}
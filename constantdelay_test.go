@@ -52,3 +52,49 @@ func TestConstantDelayNext(t *testing.T) {
 		}
 	}
 }
+
+func TestConstantDelayPrev(t *testing.T) {
+	anchor := getTime("Mon Jul 9 09:00 2012")
+	tests := []struct {
+		time  string
+		delay time.Duration
+		prev  string
+		next  string
+	}{
+		{"Mon Jul 9 15:00 2012", 15 * time.Minute, "Mon Jul 9 14:45 2012", "Mon Jul 9 15:15 2012"},
+		{"Mon Jul 9 15:07 2012", 15 * time.Minute, "Mon Jul 9 15:00 2012", "Mon Jul 9 15:15 2012"},
+		{"Tue Jul 10 00:20:15 2012", 44*time.Minute + 24*time.Second, "Mon Jul 9 23:48 2012", "Tue Jul 10 00:32:24 2012"},
+
+		// Times before the anchor
+		{"Mon Jul 9 08:59 2012", time.Hour, "Mon Jul 9 08:00 2012", "Mon Jul 9 09:00 2012"},
+		{"Sun Jul 1 09:00 2012", 7 * 24 * time.Hour, "Mon Jun 25 09:00 2012", "Mon Jul 2 09:00 2012"},
+
+		// Round to the second
+		{"Mon Jul 9 15:00:00.005 2012", 15 * time.Minute, "Mon Jul 9 15:00 2012", "Mon Jul 9 15:15 2012"},
+		{"Mon Jul 9 14:45:01 2012", 15 * time.Millisecond, "Mon Jul 9 14:45:00 2012", "Mon Jul 9 14:45:02 2012"},
+	}
+
+	for _, c := range tests {
+		schedule := EveryFrom(anchor.Add(5*time.Millisecond), c.delay)
+		if actual := schedule.Prev(getTime(c.time)); !actual.Equal(getTime(c.prev)) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.delay, getTime(c.prev), actual)
+		}
+		if actual := schedule.Next(getTime(c.time)); !actual.Equal(getTime(c.next)) {
+			t.Errorf("%s, \"%s\": Next (expected) %v != %v (actual)", c.time, c.delay, getTime(c.next), actual)
+		}
+	}
+
+	// Times are given in the location of the time asked about.
+	cet := time.FixedZone("CET", 3600)
+	schedule := EveryFrom(anchor, time.Hour)
+	if next := schedule.Next(anchor.In(cet)); next.Location() != cet {
+		t.Errorf("expected the next activation in %v, got %v", cet, next)
+	}
+	if prev := schedule.Prev(anchor.In(cet)); prev.Location() != cet {
+		t.Errorf("expected the previous activation in %v, got %v", cet, prev)
+	}
+
+	if prev := Every(time.Hour).Prev(anchor); !prev.IsZero() {
+		t.Errorf("expected no previous activation without an anchor, got %v", prev)
+	}
+}
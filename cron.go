@@ -3,8 +3,10 @@ package cron
 import (
 	"fmt"
 	"log"
+	"math/rand"
 	"runtime"
 	"sort"
+	"sync"
 	"time"
 )
 
@@ -15,10 +17,41 @@ type Cron struct {
 	entries  []*Entry
 	stop     chan struct{}
 	add      chan *Entry
+	remove   chan EntryID
+	depend   chan dependencyRequest
 	snapshot chan []*Entry
 	running  bool
 	ErrorLog *log.Logger
 	location *time.Location
+
+	// The ID of the last entry added. Entries may be added to a running Cron
+	// from several goroutines, so it is guarded by idMu.
+	idMu   sync.Mutex
+	nextID EntryID
+
+	// Completed job runs, waiting to be handled by the run loop, which is
+	// woken up through the done channel.
+	mu        sync.Mutex
+	completed []Execution
+	done      chan struct{}
+
+	// RunOnStart is the default for Entry.RunOnStart, applied to entries as
+	// they are added.
+	RunOnStart bool
+
+	// The times during which no jobs are started. See AddBlackout.
+	blackouts []Blackout
+	blackout  chan Blackout
+
+	// Rand is the source of the random delays added to the run times of entries
+	// with jitter, applied to entries as they are added. If nil, the default
+	// source of the math/rand package is used.
+	Rand *rand.Rand
+
+	// OnBlackout, if set, is called by the scheduler for each run that is
+	// skipped or deferred because of a blackout. It must not block, nor call
+	// the Cron's methods.
+	OnBlackout func(BlackoutEvent)
 }
 
 // Job is an interface for submitted cron jobs.
@@ -33,13 +66,39 @@ type Schedule interface {
 	Next(time.Time) time.Time
 }
 
+// PrevScheduler is implemented by schedules that can also tell when they were
+// last activated, for example to decide whether a job missed its last run
+// while the program was not running.
+type PrevScheduler interface {
+	Schedule
+
+	// Return the latest activation time, earlier than the given time, or the
+	// zero time if there is none.
+	Prev(time.Time) time.Time
+}
+
+// Matcher is implemented by schedules that can tell whether they activate at a
+// given time, without searching for it.
+type Matcher interface {
+	Schedule
+
+	// Report whether the schedule activates at the given time.
+	Matches(time.Time) bool
+}
+
+// EntryID identifies an entry within a Cron instance.
+type EntryID int
+
 // Entry consists of a schedule and the func to execute on that schedule.
 type Entry struct {
+	// The ID of this entry, assigned by the Cron when it is added.
+	ID EntryID
+
 	// The schedule on which this job should be run.
 	Schedule Schedule
 
-	// The next time the job will run. This is the zero time if Cron has not been
-	// started or this entry's schedule is unsatisfiable
+	// The next time the job will run, including any jitter. This is the zero
+	// time if Cron has not been started or this entry's schedule is unsatisfiable
 	Next time.Time
 
 	// The last time this job was run. This is the zero time if the job has never
@@ -48,6 +107,193 @@ type Entry struct {
 
 	// The Job to run.
 	Job Job
+
+	// A name for the entry, used to identify it to people, such as in the
+	// iCalendar feed. Optional.
+	Name string
+
+	// The spec from which the schedule was parsed, if it was added with AddJob
+	// or AddFunc.
+	Spec string
+
+	// The time zone in which the schedule is evaluated. This is the zone given
+	// by a CRON_TZ= or TZ= prefix on the spec, or else the Cron's location.
+	Location *time.Location
+
+	// The job does not run before this time. The schedule is evaluated as
+	// though the entry had been added at Start, and may activate at Start
+	// itself. Ignored if zero.
+	Start time.Time
+
+	// The job does not run after this time, and the entry is removed once it
+	// has passed. Ignored if zero.
+	End time.Time
+
+	// The maximum number of times the job is run before the entry is removed,
+	// or 0 for no limit.
+	MaxRuns int
+
+	// The number of times the job has been run.
+	Runs int
+
+	// The maximum random delay added to each time the schedule activates, to
+	// spread the load of jobs with the same schedule. The schedule itself is
+	// followed without drift.
+	Jitter time.Duration
+
+	// The time the schedule activates for the next run, before jitter.
+	scheduled time.Time
+
+	// The source of jitter, or nil for the default source.
+	rand *rand.Rand
+
+	// Run the job once when the Cron starts, or when the entry is added to a
+	// running Cron, before following the schedule.
+	RunOnStart bool
+
+	// The upstream entries whose job completions trigger this job. Entries
+	// that are only triggered by their dependencies have a nil Schedule.
+	DependsOn []Dependency
+
+	// The upstream executions that triggered the latest run of the job, or nil
+	// if it was run by its schedule.
+	TriggeredBy []Execution
+
+	// The upstream executions received since the job was last triggered by
+	// its dependencies, by upstream entry.
+	pending map[EntryID]Execution
+
+	// The upstream executions that triggered a run deferred by a blackout.
+	deferredBy []Execution
+}
+
+// EntryOption configures an Entry as it is added to the Cron.
+type EntryOption func(*Entry)
+
+// WithName returns an EntryOption that names the entry.
+func WithName(name string) EntryOption {
+	return func(e *Entry) {
+		e.Name = name
+	}
+}
+
+// WithStart returns an EntryOption that keeps the job from running before t.
+func WithStart(t time.Time) EntryOption {
+	return func(e *Entry) {
+		e.Start = t
+	}
+}
+
+// WithEnd returns an EntryOption that retires the entry after t.
+func WithEnd(t time.Time) EntryOption {
+	return func(e *Entry) {
+		e.End = t
+	}
+}
+
+// WithMaxRuns returns an EntryOption that retires the entry after the job has
+// run n times.
+func WithMaxRuns(n int) EntryOption {
+	return func(e *Entry) {
+		e.MaxRuns = n
+	}
+}
+
+// WithJitter returns an EntryOption that delays each run of the job by a
+// random duration of up to max.
+func WithJitter(max time.Duration) EntryOption {
+	return func(e *Entry) {
+		e.Jitter = max
+	}
+}
+
+// WithRunOnStart returns an EntryOption that sets whether the job runs once when
+// the Cron starts, overriding the Cron's default.
+func WithRunOnStart(run bool) EntryOption {
+	return func(e *Entry) {
+		e.RunOnStart = run
+	}
+}
+
+// first returns the first time the entry should run when the Cron starts or the
+// entry is added to a running Cron. This is now for entries that run on start,
+// unless they are outside of their validity window or run limit.
+func (e *Entry) first(now time.Time) time.Time {
+	if e.RunOnStart && e.Schedule != nil && !now.Before(e.Start) && (e.End.IsZero() || !now.After(e.End)) &&
+		(e.MaxRuns == 0 || e.Runs < e.MaxRuns) {
+		return now
+	}
+	return e.next(now)
+}
+
+// next returns the next time the entry should run after now, taking its
+// validity window, run limit and jitter into account. It returns the zero time
+// if the entry will not run again.
+func (e *Entry) next(now time.Time) time.Time {
+	if e.Schedule == nil {
+		return time.Time{}
+	}
+	if e.MaxRuns > 0 && e.Runs >= e.MaxRuns {
+		return time.Time{}
+	}
+	if now.Before(e.Start) {
+		now = resumeFrom(e.Schedule, e.Start)
+	}
+
+	// Follow on from the previous activation of the schedule, rather than from
+	// the (possibly jittered) time of the run, unless that misses activations.
+	next := time.Time{}
+	if !e.scheduled.IsZero() && e.scheduled.Before(now) {
+		next = e.Schedule.Next(e.scheduled)
+	}
+	if next.IsZero() || next.Before(now) {
+		next = e.Schedule.Next(now)
+	}
+	e.scheduled = next
+	if next.IsZero() || !e.End.IsZero() && next.After(e.End) {
+		return time.Time{}
+	}
+
+	if e.Jitter > 0 {
+		next = next.Add(e.jitter())
+		if !e.End.IsZero() && next.After(e.End) {
+			next = e.End
+		}
+	}
+	return next
+}
+
+// resumeFrom returns the time from which to search for the activations of the
+// schedule that follow a pause ending at t, such as a start time or the end of
+// an exclusion. An activation at t itself is included, by searching from just
+// before it. Otherwise the search starts at t, so that intervals follow on from
+// t rather than from the second before it.
+func resumeFrom(schedule Schedule, t time.Time) time.Time {
+	if before := t.Add(-time.Nanosecond); schedule.Next(before).Equal(t) {
+		return before
+	}
+	return t
+}
+
+// jitter returns a random duration in [0, e.Jitter).
+func (e *Entry) jitter() time.Duration {
+	if e.rand != nil {
+		return time.Duration(e.rand.Int63n(int64(e.Jitter)))
+	}
+	return time.Duration(rand.Int63n(int64(e.Jitter)))
+}
+
+// usedUp returns true if the entry may not run at now, or any later time: it
+// has reached its run limit, or its end time has passed.
+func (e *Entry) usedUp(now time.Time) bool {
+	return e.MaxRuns > 0 && e.Runs >= e.MaxRuns || !e.End.IsZero() && now.After(e.End)
+}
+
+// finished returns true if the entry will not run again and may be removed: it
+// is used up, or its schedule does not activate again and no dependency may
+// trigger it.
+func (e *Entry) finished(now time.Time) bool {
+	return e.usedUp(now) || e.Schedule != nil && e.Next.IsZero() && len(e.DependsOn) == 0
 }
 
 // byTime is a wrapper for sorting the entry array by time
@@ -85,6 +331,10 @@ func NewWithLocation(location *time.Location) *Cron {
 	return &Cron{
 		entries:  nil,
 		add:      make(chan *Entry),
+		remove:   make(chan EntryID),
+		depend:   make(chan dependencyRequest),
+		blackout: make(chan Blackout),
+		done:     make(chan struct{}, 1),
 		stop:     make(chan struct{}),
 		snapshot: make(chan []*Entry),
 		running:  false,
@@ -99,35 +349,129 @@ type FuncJob func()
 func (f FuncJob) Run() { f() }
 
 // AddFunc adds a func to the Cron to be run on the given schedule.
-func (c *Cron) AddFunc(spec string, cmd func()) error {
-	return c.AddJob(spec, FuncJob(cmd))
+// The spec is parsed using the time zone of this Cron instance as the default.
+func (c *Cron) AddFunc(spec string, cmd func(), opts ...EntryOption) error {
+	_, err := c.AddFuncEntry(spec, cmd, opts...)
+	return err
+}
+
+// AddFuncEntry is like AddFunc, and also returns the ID of the entry, which may
+// be used to remove it or to make other entries depend on it.
+func (c *Cron) AddFuncEntry(spec string, cmd func(), opts ...EntryOption) (EntryID, error) {
+	return c.AddJobEntry(spec, FuncJob(cmd), opts...)
 }
 
 // AddJob adds a Job to the Cron to be run on the given schedule.
-func (c *Cron) AddJob(spec string, cmd Job) error {
-	schedule, err := Parse(spec)
+// The name of the entry, if given with WithName, is the hash key for "H" fields
+// in the spec.
+func (c *Cron) AddJob(spec string, cmd Job, opts ...EntryOption) error {
+	_, err := c.AddJobEntry(spec, cmd, opts...)
+	return err
+}
+
+// AddJobEntry is like AddJob, and also returns the ID of the entry, which may be
+// used to remove it or to make other entries depend on it.
+func (c *Cron) AddJobEntry(spec string, cmd Job, opts ...EntryOption) (EntryID, error) {
+	var e Entry
+	for _, opt := range opts {
+		opt(&e)
+	}
+	schedule, err := defaultParser.WithHashKey(e.Name).Parse(spec)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	opts = append([]EntryOption{withSpec(spec)}, opts...)
+	return c.ScheduleEntry(schedule, cmd, opts...)
+}
+
+// withSpec returns an EntryOption that records the spec of the entry.
+func withSpec(spec string) EntryOption {
+	return func(e *Entry) {
+		e.Spec = spec
 	}
-	c.Schedule(schedule, cmd)
-	return nil
 }
 
 // Schedule adds a Job to the Cron to be run on the given schedule.
-func (c *Cron) Schedule(schedule Schedule, cmd Job) {
-	fmt.Println("before append entry len: ", len(c.entries))
+// Schedules that are exhausted, such as one-shot schedules in the past, are
+// refused, since the job would never run. Schedule has no return value, so that
+// existing callers still compile, and only logs the refusal; use ScheduleEntry
+// to get it as an error.
+func (c *Cron) Schedule(schedule Schedule, cmd Job, opts ...EntryOption) {
+	if _, err := c.ScheduleEntry(schedule, cmd, opts...); err != nil {
+		c.logf("cron: %v", err)
+	}
+}
+
+// ScheduleEntry adds a Job to the Cron to be run on the given schedule, as
+// Schedule does, and returns the ID of the entry, which may be used to remove it
+// or to make other entries depend on it. It returns an error if the schedule is
+// nil or exhausted, such as a one-shot schedule in the past, so that the job
+// would never run.
+func (c *Cron) ScheduleEntry(schedule Schedule, cmd Job, opts ...EntryOption) (EntryID, error) {
+	if schedule == nil {
+		return 0, fmt.Errorf("Schedule is nil")
+	}
+	return c.addEntry(schedule, cmd, opts...)
+}
+
+// addEntry adds a Job to the Cron to be run on the given schedule, or only when
+// triggered by its dependencies if the schedule is nil.
+func (c *Cron) addEntry(schedule Schedule, cmd Job, opts ...EntryOption) (EntryID, error) {
 	entry := &Entry{
-		Schedule: schedule,
-		Job:      cmd,
+		Schedule:   schedule,
+		Job:        cmd,
+		Location:   c.location,
+		RunOnStart: c.RunOnStart,
+		rand:       c.Rand,
 	}
+	inner := schedule
+	if spec, ok := schedule.(Spec); ok {
+		entry.Spec, inner = spec.Text, spec.Schedule
+	}
+	if s, ok := inner.(*SpecSchedule); ok && s.Location != nil {
+		entry.Location = s.Location
+	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+
+	// Check that the schedule activates within the validity window. Jitter is
+	// left out: the Cron's source of random numbers belongs to the run loop.
+	now := c.now()
+	if schedule != nil {
+		from := now
+		if from.Before(entry.Start) {
+			from = resumeFrom(schedule, entry.Start)
+		}
+		if next := schedule.Next(from); next.IsZero() || !entry.End.IsZero() && next.After(entry.End) {
+			return 0, fmt.Errorf("Schedule has no activation after %s", now.Format(time.RFC3339))
+		}
+	}
+
+	c.idMu.Lock()
+	c.nextID++
+	entry.ID = c.nextID
+	c.idMu.Unlock()
 	if !c.running {
 		fmt.Println("not running, append entries")
 		c.entries = append(c.entries, entry)
 		fmt.Println("after append entry len: ", len(c.entries))
-		return
+		return entry.ID, nil
 	}
 
 	c.add <- entry
+	return entry.ID, nil
+}
+
+// Remove an entry from being run in the future. Entries that depend on it are
+// no longer triggered by its job, and those that are only triggered by their
+// dependencies are removed along with it once they have no upstream entry left.
+func (c *Cron) Remove(id EntryID) {
+	if c.running {
+		c.remove <- id
+		return
+	}
+	c.removeEntry(id)
 }
 
 // Entries returns a snapshot of the cron entries.
@@ -167,7 +511,9 @@ func (c *Cron) Run() {
 	c.run()
 }
 
-func (c *Cron) runWithRecovery(j Job) {
+// runWithRecovery runs the job of the given execution, and reports it as
+// completed. The execution succeeds if the job does not panic.
+func (c *Cron) runWithRecovery(j Job, ex Execution) {
 	defer func() {
 		if r := recover(); r != nil {
 			const size = 64 << 10
@@ -175,8 +521,21 @@ func (c *Cron) runWithRecovery(j Job) {
 			buf = buf[:runtime.Stack(buf, false)]
 			c.logf("cron: panic running job: %v\n%s", r, buf)
 		}
+		c.complete(ex)
 	}()
 	j.Run()
+	ex.Success = true
+}
+
+// complete queues the completed execution for the run loop, without blocking.
+func (c *Cron) complete(ex Execution) {
+	c.mu.Lock()
+	c.completed = append(c.completed, ex)
+	c.mu.Unlock()
+	select {
+	case c.done <- struct{}{}:
+	default:
+	}
 }
 
 // Run the scheduler. this is private just due to the need to synchronize
@@ -189,8 +548,9 @@ func (c *Cron) run() {
 	for _, entry := range c.entries {
 		fmt.Println("range for entry: ", entry)
 		fmt.Println("first in next: ")
-		entry.Next = entry.Schedule.Next(now)
+		entry.Next = entry.first(now)
 	}
+	c.removeFinished(now)
 
 	for {
 		// Determine the next entry to run.
@@ -224,17 +584,49 @@ func (c *Cron) run() {
 						break
 					}
 					fmt.Println("e.func", e.Job)
-					go c.runWithRecovery(e.Job)
+					if c.holdBack(e, e.Next, now, e.deferredBy) {
+						continue
+					}
 					e.Prev = e.Next
-					e.Next = e.Schedule.Next(now)
+					e.Runs++
+					e.TriggeredBy, e.deferredBy = e.deferredBy, nil
+					go c.runWithRecovery(e.Job, Execution{Entry: e.ID, Run: e.Runs, Time: e.Prev})
+					e.Next = e.next(now)
 				}
+				c.removeFinished(now)
 
 			case newEntry := <-c.add:
 				fmt.Println("in case add: ", newEntry)
 				timer.Stop()
 				now = c.now()
-				newEntry.Next = newEntry.Schedule.Next(now)
+				newEntry.Next = newEntry.first(now)
 				c.entries = append(c.entries, newEntry)
+				c.removeFinished(now)
+
+			case id := <-c.remove:
+				timer.Stop()
+				now = c.now()
+				c.removeEntry(id)
+
+			case req := <-c.depend:
+				req.err <- c.addDependency(req.downstream, req.upstream, req.cond)
+				continue
+
+			case b := <-c.blackout:
+				c.blackouts = append(c.blackouts, b)
+				continue
+
+			case <-c.done:
+				timer.Stop()
+				now = c.now()
+				c.mu.Lock()
+				completed := c.completed
+				c.completed = nil
+				c.mu.Unlock()
+				for _, ex := range completed {
+					c.trigger(ex, now)
+				}
+				c.removeFinished(now)
 
 			case sn := <-c.snapshot:
 				fmt.Println("receive snapshot: ", sn)
@@ -276,16 +668,56 @@ func (c *Cron) Stop() {
 func (c *Cron) entrySnapshot() []*Entry {
 	entries := []*Entry{}
 	for _, e := range c.entries {
-		entries = append(entries, &Entry{
-			Schedule: e.Schedule,
-			Next:     e.Next,
-			Prev:     e.Prev,
-			Job:      e.Job,
-		})
+		entry := *e
+		entries = append(entries, &entry)
 	}
 	return entries
 }
 
+// entry returns the entry with the given ID, or nil if there is none.
+func (c *Cron) entry(id EntryID) *Entry {
+	for _, e := range c.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// removeEntry removes the entry with the given ID, along with the dependencies
+// of other entries on it.
+func (c *Cron) removeEntry(id EntryID) {
+	entries := c.entries[:0]
+	var orphans []EntryID
+	for _, e := range c.entries {
+		if e.ID == id {
+			continue
+		}
+		if e.removeDependency(id) && e.Schedule == nil && len(e.DependsOn) == 0 {
+			// Nothing can trigger the entry any more.
+			orphans = append(orphans, e.ID)
+		}
+		entries = append(entries, e)
+	}
+	c.entries = entries
+	for _, orphan := range orphans {
+		c.removeEntry(orphan)
+	}
+}
+
+// removeFinished removes the entries that will not run again, such as one-shot
+// schedules that have run and entries past their end time or run limit.
+func (c *Cron) removeFinished(now time.Time) {
+	entries := c.entries[:0]
+	for _, e := range c.entries {
+		if e.finished(now) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	c.entries = entries
+}
+
 // now returns current time in c location
 func (c *Cron) now() time.Time {
 	return time.Now().In(c.location)
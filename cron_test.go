@@ -1,6 +1,8 @@
 package cron
 
 import (
+	"math/rand"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -352,3 +354,338 @@ func stop(cron *Cron) chan bool {
 	}()
 	return ch
 }
+
+// Test that entries report the time zone their schedule is evaluated in.
+func TestEntryLocation(t *testing.T) {
+	cron := NewWithLocation(time.UTC)
+	cron.AddFunc("CRON_TZ=Asia/Tokyo 0 0 9 * * *", func() {})
+	cron.AddFunc("0 0 9 * * *", func() {})
+	cron.Schedule(Every(time.Minute), FuncJob(func() {}))
+
+	expecteds := []string{"Asia/Tokyo", "UTC", "UTC"}
+	for i, entry := range cron.Entries() {
+		if actual := entry.Location.String(); actual != expecteds[i] {
+			t.Errorf("entry %d: (expected) %s != %s (actual)", i, expecteds[i], actual)
+		}
+	}
+}
+
+// Test that a one-shot entry runs once and is then removed.
+func TestOnceEntryIsRemoved(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron := New()
+	cron.Schedule(At(time.Now().Add(time.Second)), FuncJob(func() { wg.Done() }))
+	cron.AddFunc("0 0 0 1 1 ?", func() {})
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(2 * OneSecond):
+		t.Fatal("expected one-shot job runs")
+	case <-wait(wg):
+	}
+
+	if entries := cron.Entries(); len(entries) != 1 {
+		t.Errorf("expected the finished entry to be removed, found %d entries", len(entries))
+	}
+}
+
+// limitedSchedule activates every second for its first n calls to Next, and
+// then never again.
+type limitedSchedule struct{ n int }
+
+func (s *limitedSchedule) Next(t time.Time) time.Time {
+	if s.n <= 0 {
+		return time.Time{}
+	}
+	s.n--
+	return t.Add(time.Second)
+}
+
+// Test that an entry whose schedule stops activating before it ever runs is
+// removed.
+func TestExhaustedEntryIsRemoved(t *testing.T) {
+	cron := New()
+	if _, err := cron.ScheduleEntry(&limitedSchedule{n: 1}, FuncJob(func() {})); err != nil {
+		t.Fatal(err)
+	}
+	cron.AddFunc("0 0 0 1 1 ?", func() {})
+	cron.Start()
+	defer cron.Stop()
+
+	if entries := cron.Entries(); len(entries) != 1 {
+		t.Errorf("expected the exhausted entry to be removed, found %d entries", len(entries))
+	}
+}
+
+// Test that entries whose schedule would never run again are refused.
+func TestScheduleExhausted(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	y2k, err := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year).Parse("0 0 0 1 1 ? 2000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron := New()
+	tests := []struct {
+		schedule Schedule
+		opts     []EntryOption
+	}{
+		{At(past), nil},
+		{Every(time.Minute), []EntryOption{WithEnd(past)}},
+		{y2k, nil},
+		{nil, nil},
+	}
+	for _, c := range tests {
+		if id, err := cron.ScheduleEntry(c.schedule, FuncJob(func() {}), c.opts...); err == nil {
+			t.Errorf("%+v: expected an error, got entry %d", c.schedule, id)
+		}
+	}
+	if entries := cron.Entries(); len(entries) != 0 {
+		t.Errorf("expected no entries, found %d", len(entries))
+	}
+
+	id, err := cron.ScheduleEntry(Every(time.Minute), FuncJob(func() {}))
+	if err != nil || id != 1 {
+		t.Errorf("expected entry 1, got %d, %v", id, err)
+	}
+}
+
+// Test that entries added to a running Cron from several goroutines get distinct
+// IDs.
+func TestAddConcurrently(t *testing.T) {
+	cron := New()
+	cron.Start()
+	defer cron.Stop()
+
+	const n = 10
+	ids := make(chan EntryID, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := cron.AddFuncEntry("@hourly", func() {})
+			if err != nil {
+				t.Error(err)
+			}
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[EntryID]bool{}
+	for id := range ids {
+		if seen[id] || id < 1 || id > n {
+			t.Errorf("unexpected entry %d", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestEntryNext(t *testing.T) {
+	hourly, _ := Parse("@hourly")
+	tests := []struct {
+		time     string
+		schedule Schedule
+		opts     []EntryOption
+		runs     int
+		expected string
+	}{
+		// No restrictions
+		{"Mon Jul 9 14:45 2012", hourly, nil, 0, "Mon Jul 9 15:00 2012"},
+
+		// Start in the future: evaluated as though added at Start
+		{"Mon Jul 9 14:45 2012", hourly, []EntryOption{WithStart(getTime("Mon Jul 9 17:30 2012"))}, 0, "Mon Jul 9 18:00 2012"},
+		{"Mon Jul 9 14:45 2012", hourly, []EntryOption{WithStart(getTime("Mon Jul 9 17:00 2012"))}, 0, "Mon Jul 9 17:00 2012"},
+		{"Mon Jul 9 14:45 2012", At(getTime("Mon Jul 9 17:00 2012")), []EntryOption{WithStart(getTime("Mon Jul 9 17:00 2012"))}, 0, "Mon Jul 9 17:00 2012"},
+		{"Mon Jul 9 14:45 2012", Every(10 * time.Minute), []EntryOption{WithStart(getTime("Mon Jul 9 17:30 2012"))}, 0, "Mon Jul 9 17:40 2012"},
+		{"Mon Jul 9 14:45 2012", hourly, []EntryOption{WithStart(getTime("Mon Jul 9 12:00 2012"))}, 0, "Mon Jul 9 15:00 2012"},
+
+		// End
+		{"Mon Jul 9 14:45 2012", hourly, []EntryOption{WithEnd(getTime("Mon Jul 9 15:00 2012"))}, 0, "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 14:45 2012", hourly, []EntryOption{WithEnd(getTime("Mon Jul 9 14:59 2012"))}, 0, ""},
+
+		// Max runs
+		{"Mon Jul 9 14:45 2012", hourly, []EntryOption{WithMaxRuns(3)}, 2, "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 14:45 2012", hourly, []EntryOption{WithMaxRuns(3)}, 3, ""},
+	}
+
+	for _, c := range tests {
+		e := &Entry{Schedule: c.schedule, Runs: c.runs}
+		for _, opt := range c.opts {
+			opt(e)
+		}
+		actual := e.next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, %v: (expected) %v != %v (actual)", c.time, c.schedule, expected, actual)
+		}
+	}
+}
+
+// Test that the entry name is the hash key for H fields.
+func TestEntryHashKey(t *testing.T) {
+	cron := New()
+	cron.AddFunc("0 H H * * *", func() {}, WithName("tenant-1"))
+	expected, _ := defaultParser.WithHashKey("tenant-1").Parse("0 H H * * *")
+	if actual := cron.Entries()[0].Schedule; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+}
+
+// Test that jitter delays each run by less than the maximum, without the
+// schedule drifting.
+func TestEntryJitter(t *testing.T) {
+	hourly, _ := Parse("@hourly")
+	for _, schedule := range []Schedule{hourly, Every(time.Hour)} {
+		e := &Entry{Schedule: schedule, Jitter: 10 * time.Minute, rand: rand.New(rand.NewSource(1))}
+		from := getTime("Mon Jul 9 15:00 2012")
+		now := from
+		jittered := false
+		for i := 1; i <= 100; i++ {
+			now = e.next(now)
+			scheduled := from.Add(time.Duration(i) * time.Hour)
+			if delay := now.Sub(scheduled); delay < 0 || delay >= e.Jitter {
+				t.Fatalf("%v, run %d: expected up to %v after %v, got %v", schedule, i, e.Jitter, scheduled, now)
+			}
+			jittered = jittered || !now.Equal(scheduled)
+		}
+		if !jittered {
+			t.Errorf("%v: expected some runs to be delayed", schedule)
+		}
+	}
+
+	// Jitter is reproducible with a seeded source.
+	var nexts []time.Time
+	for i := 0; i < 2; i++ {
+		cron := New()
+		cron.Rand = rand.New(rand.NewSource(42))
+		cron.AddFunc("@hourly", func() {}, WithJitter(time.Hour))
+		cron.Start()
+		nexts = append(nexts, cron.Entries()[0].Next)
+		cron.Stop()
+	}
+	if !nexts[0].Equal(nexts[1]) || nexts[0].Minute() == 0 && nexts[0].Second() == 0 {
+		t.Errorf("expected the same jittered times, got %v", nexts)
+	}
+}
+
+// Test that adding entries leaves the Cron's source of random numbers to the
+// run loop, so that the jitter of the first run is the first number drawn.
+func TestScheduleJitterSource(t *testing.T) {
+	cron := New()
+	cron.Rand = rand.New(rand.NewSource(42))
+	hourly, _ := Parse("@hourly")
+	id, err := cron.ScheduleEntry(hourly, FuncJob(func() {}), WithJitter(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron.Schedule(Every(time.Hour), FuncJob(func() {}), WithJitter(time.Hour))
+	cron.Start()
+	defer cron.Stop()
+
+	var next time.Time
+	for _, e := range cron.Entries() {
+		if e.ID == id {
+			next = e.Next
+		}
+	}
+	scheduled := hourly.(*SpecSchedule).Prev(next.Add(time.Nanosecond))
+	expected := time.Duration(rand.New(rand.NewSource(42)).Int63n(int64(time.Hour)))
+	if delay := next.Sub(scheduled); delay != expected {
+		t.Errorf("(expected) %v != %v (actual)", expected, delay)
+	}
+}
+
+// Test that an entry with a run limit is removed after running that many times.
+func TestEntryMaxRuns(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+
+	cron := New()
+	cron.AddFunc("* * * * * ?", func() { mu.Lock(); calls++; mu.Unlock() }, WithMaxRuns(2))
+	cron.AddFunc("0 0 0 1 1 ?", func() {})
+	cron.Start()
+	defer cron.Stop()
+
+	<-time.After(3 * OneSecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Errorf("called %d times, expected 2", calls)
+	}
+	if entries := cron.Entries(); len(entries) != 1 || entries[0].MaxRuns != 0 {
+		t.Errorf("expected the entry to be removed after its last run, found %d entries", len(entries))
+	}
+}
+
+// Test that entries report their validity window, and that entries past their
+// end time are removed when cron starts.
+func TestEntryValidityWindow(t *testing.T) {
+	start := time.Now().Add(time.Hour)
+	end := start.Add(time.Hour)
+
+	cron := New()
+	cron.AddFunc("* * * * * ?", func() {}, WithStart(start), WithEnd(end))
+	cron.AddFunc("* * * * * ?", func() {}, WithEnd(time.Now().Add(-time.Second)))
+	cron.Start()
+	defer cron.Stop()
+
+	entries := cron.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected the expired entry to be removed, found %d entries", len(entries))
+	}
+	if e := entries[0]; !e.Start.Equal(start) || !e.End.Equal(end) || e.Next.Before(start) {
+		t.Errorf("unexpected entry window: start %v, end %v, next %v", e.Start, e.End, e.Next)
+	}
+}
+
+// Test that entries that run on start run right away, and then follow their
+// schedule.
+func TestRunOnStart(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+
+	cron := New()
+	cron.AddFunc("@every 1h", func() { wg.Done() }, WithRunOnStart(true))
+	cron.AddFunc("@every 1h", func() { t.Error("expected job without run on start does not run") })
+	cron.Start()
+	defer cron.Stop()
+
+	// Entries added to a running Cron run right away too.
+	cron.AddFunc("@every 1h", func() { wg.Done() }, WithRunOnStart(true))
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected jobs run on start")
+	case <-wait(wg):
+	}
+
+	for _, e := range cron.Entries() {
+		if e.RunOnStart && (e.Runs != 1 || e.Prev.IsZero() || e.Next.Sub(e.Prev) < 59*time.Minute) {
+			t.Errorf("expected a normal run on start, got runs %d, prev %v, next %v", e.Runs, e.Prev, e.Next)
+		}
+	}
+}
+
+// Test that the Cron's default applies to new entries unless overridden.
+func TestRunOnStartDefault(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron := New()
+	cron.RunOnStart = true
+	cron.AddFunc("@every 1h", func() { wg.Done() })
+	cron.AddFunc("@every 1h", func() { t.Error("expected job without run on start does not run") }, WithRunOnStart(false))
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job runs on start")
+	case <-wait(wg):
+	}
+}
@@ -0,0 +1,186 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+)
+
+// Condition selects which completions of an upstream job trigger the entries
+// that depend on it.
+type Condition int
+
+const (
+	OnSuccess    Condition = iota // The upstream job returned without panicking
+	OnCompletion                  // The upstream job returned or panicked
+)
+
+// Dependency is an edge of the job dependency graph. The entry holding it runs
+// after completions of the Upstream entry's job that satisfy the Condition.
+type Dependency struct {
+	Upstream  EntryID
+	Condition Condition
+}
+
+// Execution identifies one run of an entry's job.
+type Execution struct {
+	// The entry whose job was run.
+	Entry EntryID
+
+	// The number of the run, counting from 1.
+	Run int
+
+	// The time the run was due.
+	Time time.Time
+
+	// Whether the job returned without panicking. This is only known once the
+	// run has completed.
+	Success bool
+}
+
+// dependencyRequest asks the run loop to add a dependency, and receives the result.
+type dependencyRequest struct {
+	downstream, upstream EntryID
+	cond                 Condition
+	err                  chan error
+}
+
+// AddDependent adds a Job to the Cron that is run after the jobs of all of the
+// upstream entries have completed, according to cond, instead of on a schedule.
+// With several upstream entries, the job runs once each of them has completed
+// since it last ran.
+// It returns an error if an upstream entry does not exist.
+func (c *Cron) AddDependent(cmd Job, cond Condition, upstream ...EntryID) (EntryID, error) {
+	if len(upstream) == 0 {
+		return 0, fmt.Errorf("Dependent job needs at least one upstream entry")
+	}
+	id, err := c.addEntry(nil, cmd)
+	if err != nil {
+		return 0, err
+	}
+	for _, up := range upstream {
+		if err := c.AddDependency(id, up, cond); err != nil {
+			c.Remove(id)
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// AddDependency makes the downstream entry run after each completion of the
+// upstream entry's job that satisfies cond, in addition to its schedule (if any).
+// A later dependency on the same upstream entry replaces the earlier one.
+// It returns an error if either entry does not exist, or if the dependency would
+// create a cycle.
+func (c *Cron) AddDependency(downstream, upstream EntryID, cond Condition) error {
+	if c.running {
+		req := dependencyRequest{downstream, upstream, cond, make(chan error)}
+		c.depend <- req
+		return <-req.err
+	}
+	return c.addDependency(downstream, upstream, cond)
+}
+
+func (c *Cron) addDependency(downstream, upstream EntryID, cond Condition) error {
+	d, u := c.entry(downstream), c.entry(upstream)
+	if d == nil {
+		return fmt.Errorf("Unknown downstream entry: %d", downstream)
+	}
+	if u == nil {
+		return fmt.Errorf("Unknown upstream entry: %d", upstream)
+	}
+	if c.reaches(upstream, downstream) {
+		return fmt.Errorf("Dependency of entry %d on entry %d would create a cycle", downstream, upstream)
+	}
+	deps := append([]Dependency(nil), d.DependsOn...)
+	for i, dep := range deps {
+		if dep.Upstream == upstream {
+			deps[i].Condition = cond
+			d.DependsOn = deps
+			return nil
+		}
+	}
+	d.DependsOn = append(deps, Dependency{upstream, cond})
+	return nil
+}
+
+// reaches returns true if the entry from depends on the entry to, directly or
+// through other entries, or if they are the same entry.
+func (c *Cron) reaches(from, to EntryID) bool {
+	seen := map[EntryID]bool{}
+	var visit func(id EntryID) bool
+	visit = func(id EntryID) bool {
+		if id == to {
+			return true
+		}
+		if seen[id] {
+			return false
+		}
+		seen[id] = true
+		if e := c.entry(id); e != nil {
+			for _, dep := range e.DependsOn {
+				if visit(dep.Upstream) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return visit(from)
+}
+
+// trigger records the completed execution with the entries that depend on it,
+// and runs those whose dependencies are all satisfied. Completions outside an
+// entry's validity window, or once it has reached its run limit, are ignored.
+func (c *Cron) trigger(ex Execution, now time.Time) {
+	for _, e := range c.entries {
+		if !e.satisfiedBy(ex) || now.Before(e.Start) || e.usedUp(now) {
+			continue
+		}
+		if e.pending == nil {
+			e.pending = map[EntryID]Execution{}
+		}
+		e.pending[ex.Entry] = ex
+		if len(e.pending) < len(e.DependsOn) {
+			continue
+		}
+
+		triggeredBy := make([]Execution, 0, len(e.DependsOn))
+		for _, dep := range e.DependsOn {
+			triggeredBy = append(triggeredBy, e.pending[dep.Upstream])
+		}
+		e.pending = nil
+		if c.holdBack(e, now, now, triggeredBy) {
+			continue
+		}
+		e.TriggeredBy = triggeredBy
+		e.Prev = now
+		e.Runs++
+		go c.runWithRecovery(e.Job, Execution{Entry: e.ID, Run: e.Runs, Time: now})
+	}
+}
+
+// satisfiedBy returns true if the entry depends on the execution's entry, with a
+// condition that the execution satisfies.
+func (e *Entry) satisfiedBy(ex Execution) bool {
+	for _, dep := range e.DependsOn {
+		if dep.Upstream == ex.Entry && (ex.Success || dep.Condition == OnCompletion) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeDependency removes the entry's dependencies on the given upstream entry,
+// and reports whether it had any.
+func (e *Entry) removeDependency(upstream EntryID) bool {
+	var deps []Dependency
+	for _, dep := range e.DependsOn {
+		if dep.Upstream != upstream {
+			deps = append(deps, dep)
+		}
+	}
+	removed := len(deps) < len(e.DependsOn)
+	e.DependsOn = deps
+	delete(e.pending, upstream)
+	return removed
+}
@@ -0,0 +1,185 @@
+package cron
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddDependency(t *testing.T) {
+	cron := New()
+	extract, _ := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}))
+	transform, err := cron.AddDependent(FuncJob(func() {}), OnSuccess, extract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish, err := cron.AddDependent(FuncJob(func() {}), OnSuccess, transform)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		downstream, upstream EntryID
+		err                  string
+	}{
+		{extract, extract, "would create a cycle"},
+		{extract, transform, "would create a cycle"},
+		{extract, publish, "would create a cycle"},
+		{transform, publish, "would create a cycle"},
+		{publish, 42, "Unknown upstream entry"},
+		{42, publish, "Unknown downstream entry"},
+		{publish, extract, ""},
+	}
+
+	for _, c := range tests {
+		err := cron.AddDependency(c.downstream, c.upstream, OnCompletion)
+		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%d on %d => expected %v, got %v", c.downstream, c.upstream, c.err, err)
+		}
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%d on %d => unexpected error %v", c.downstream, c.upstream, err)
+		}
+	}
+
+	if _, err := cron.AddDependent(FuncJob(func() {}), OnSuccess, extract, 42); err == nil {
+		t.Error("expected an error adding a dependent on an unknown entry")
+	}
+	if entries := cron.Entries(); len(entries) != 3 {
+		t.Errorf("expected the failed dependent to be removed, found %d entries", len(entries))
+	}
+}
+
+// Test that removing an entry removes the dependents left with no upstream
+// entry, and keeps the others.
+func TestRemoveUpstream(t *testing.T) {
+	cron := New()
+	extract, _ := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}))
+	other, _ := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}))
+	transform, _ := cron.AddDependent(FuncJob(func() {}), OnSuccess, extract)
+	cron.AddDependent(FuncJob(func() {}), OnSuccess, transform)
+	audit, _ := cron.AddDependent(FuncJob(func() {}), OnSuccess, extract, other)
+	check, _ := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}))
+	if err := cron.AddDependency(check, extract, OnSuccess); err != nil {
+		t.Fatal(err)
+	}
+
+	cron.Remove(extract)
+	var ids []EntryID
+	for _, e := range cron.Entries() {
+		ids = append(ids, e.ID)
+		if len(e.DependsOn) > 0 && e.DependsOn[0].Upstream == extract {
+			t.Errorf("expected entry %d not to depend on the removed entry", e.ID)
+		}
+	}
+	if len(ids) != 3 || ids[0] != other || ids[1] != audit || ids[2] != check {
+		t.Errorf("expected entries %d, %d and %d, found %v", other, audit, check, ids)
+	}
+}
+
+// Test a pipeline with fan-out and fan-in: extract, then transform and load,
+// then publish.
+func TestDependentsRun(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron := New()
+	extract, _ := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}), WithRunOnStart(true))
+	transform, _ := cron.AddDependent(FuncJob(func() {}), OnSuccess, extract)
+	load, _ := cron.AddDependent(FuncJob(func() {}), OnSuccess, extract)
+	publish, _ := cron.AddDependent(FuncJob(func() { wg.Done() }), OnSuccess, transform, load)
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected the pipeline runs")
+	case <-wait(wg):
+	}
+
+	triggers := map[EntryID][]Execution{}
+	for _, e := range cron.Entries() {
+		triggers[e.ID] = e.TriggeredBy
+	}
+	expecteds := map[EntryID][]EntryID{
+		extract:   nil,
+		transform: {extract},
+		load:      {extract},
+		publish:   {transform, load},
+	}
+	for id, upstream := range expecteds {
+		if len(triggers[id]) != len(upstream) {
+			t.Errorf("entry %d: expected to be triggered by %v, got %v", id, upstream, triggers[id])
+			continue
+		}
+		for i, ex := range triggers[id] {
+			if ex.Entry != upstream[i] || ex.Run != 1 || !ex.Success || ex.Time.IsZero() {
+				t.Errorf("entry %d: unexpected trigger %+v", id, ex)
+			}
+		}
+	}
+}
+
+// Test that only dependents on any completion run after a job panics.
+func TestDependentsOnFailure(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	cron := New()
+	extract, _ := cron.ScheduleEntry(Every(time.Hour), DummyJob{}, WithRunOnStart(true))
+	cron.AddDependent(FuncJob(func() { t.Error("expected job on success does not run") }), OnSuccess, extract)
+	cleanup, _ := cron.AddDependent(FuncJob(func() { wg.Done() }), OnCompletion, extract)
+	cron.Start()
+	defer cron.Stop()
+
+	select {
+	case <-time.After(OneSecond):
+		t.Fatal("expected job on completion runs")
+	case <-wait(wg):
+	}
+
+	for _, e := range cron.Entries() {
+		if e.ID == cleanup && (len(e.TriggeredBy) != 1 || e.TriggeredBy[0].Success) {
+			t.Errorf("expected to be triggered by a failed run, got %+v", e.TriggeredBy)
+		}
+	}
+}
+
+// Test that dependencies only trigger entries within their validity window and
+// run limit, and that entries are removed once they are used up.
+func TestDependentsLimits(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		opts     []EntryOption
+		triggers []time.Duration
+		runs     int
+		removed  bool
+	}{
+		{nil, []time.Duration{0, time.Minute, 2 * time.Minute}, 3, false},
+		{[]EntryOption{WithMaxRuns(1)}, []time.Duration{0, time.Minute, 2 * time.Minute}, 1, true},
+		{[]EntryOption{WithStart(now.Add(time.Minute))}, []time.Duration{0, 2 * time.Minute}, 1, false},
+		{[]EntryOption{WithEnd(now.Add(90 * time.Minute))}, []time.Duration{0, time.Hour, 2 * time.Hour}, 2, true},
+	}
+	for i, c := range tests {
+		cron := New()
+		upstream, _ := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}))
+		downstream, err := cron.ScheduleEntry(Every(time.Hour), FuncJob(func() {}), c.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cron.AddDependency(downstream, upstream, OnCompletion); err != nil {
+			t.Fatal(err)
+		}
+		e := cron.entry(downstream)
+		for _, d := range c.triggers {
+			cron.trigger(Execution{Entry: upstream, Run: 1, Success: true}, now.Add(d))
+			cron.removeFinished(now.Add(d))
+		}
+		if e.Runs != c.runs {
+			t.Errorf("%d: expected %d runs, got %d", i, c.runs, e.Runs)
+		}
+		if removed := cron.entry(downstream) == nil; removed != c.removed {
+			t.Errorf("%d: expected removed %v, got %v", i, c.removed, removed)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// UnitPhrases are the phrases that describe the values of a field, or an
+// interval, in a unit such as minutes.
+type UnitPhrases struct {
+	Every      string // Every value, as in "every minute"
+	EveryN     string // Every n values from the first, as in "every %d minutes"
+	EveryNFrom string // Every n values within a range, as in "every %d minutes from %d through %d"
+	At         string // One value, as in "at minute %s"
+	AtList     string // Several values and ranges, as in "at minutes %s"
+}
+
+// Phrases are the words that descriptions of schedules are made of, so that
+// schedules may be described in other languages than English. The format
+// strings take their arguments in the order of the English ones.
+type Phrases struct {
+	// Lists of values, as in "1, 2 and 3", and ranges, as in "1 through 5".
+	Comma, And, Through string
+
+	// The time of day fields, and intervals.
+	Second, Minute, Hour UnitPhrases
+
+	// Times of day, as in "at 09:30 and 17:30", and a range of hours, as in
+	// "between 09:00 and 17:59".
+	AtTimes, Between string
+
+	// Days of the month. The At and AtList phrases of DayOfMonth name the days,
+	// as in "day 1" and "days 1 through 7", and are framed by OnDaysOfMonth
+	// along with the last days and nearest weekdays.
+	DayOfMonth                                              UnitPhrases
+	OnDaysOfMonth                                           string
+	LastDay, DaysBeforeLastDay, NearestWeekday, LastWeekday string
+
+	// Days of the week, starting from Sunday, and the last and nth days of
+	// week of the month, as in "the last Friday of the month" and "the second
+	// Monday of the month".
+	Weekdays                            [7]string
+	Ordinals                            [5]string
+	OnWeekdays, LastOfMonth, NthOfMonth string
+
+	// Both days of month and of week: either one, or both of them.
+	DaysOr, DaysAnd string
+
+	// Months, starting from January, and years.
+	Months            [12]string
+	InMonths, InYears string
+	EveryNYears       string
+
+	// The time zone, a one-off time (with its layout), an interval anchored to
+	// a time (in the same layout), a schedule that skips the times excluded by
+	// a calendar, and any other schedule.
+	Location, Once, OnceLayout, Anchored, Excluding, Custom string
+}
+
+// English are the phrases of descriptions in English.
+var English = &Phrases{
+	Comma:   "%s, %s",
+	And:     "%s and %s",
+	Through: "%s through %s",
+
+	Second: UnitPhrases{"every second", "every %d seconds", "every %d seconds from second %d through %d", "at second %s", "at seconds %s"},
+	Minute: UnitPhrases{"every minute", "every %d minutes", "every %d minutes from minute %d through %d", "at minute %s", "at minutes %s"},
+	Hour:   UnitPhrases{"every hour", "every %d hours", "every %d hours from hour %d through %d", "during hour %s", "during hours %s"},
+
+	AtTimes: "at %s",
+	Between: "between %s and %s",
+
+	DayOfMonth:        UnitPhrases{"every day", "every %d days of the month", "every %d days of the month from day %d through %d", "day %s", "days %s"},
+	OnDaysOfMonth:     "on %s of the month",
+	LastDay:           "the last day",
+	DaysBeforeLastDay: "%d days before the last day",
+	NearestWeekday:    "the weekday nearest day %d",
+	LastWeekday:       "the last weekday",
+
+	Weekdays:    [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	Ordinals:    [5]string{"first", "second", "third", "fourth", "fifth"},
+	OnWeekdays:  "on %s",
+	LastOfMonth: "the last %s of the month",
+	NthOfMonth:  "the %s %s of the month",
+
+	DaysOr:  "%s, or %s",
+	DaysAnd: "%s, but only %s",
+
+	Months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	InMonths:    "in %s",
+	InYears:     "in %s",
+	EveryNYears: "every %d years from %d through %d",
+
+	Location:   "(%s)",
+	Once:       "once, at %s",
+	OnceLayout: "2006-01-02 15:04:05 MST",
+	Anchored:   "%s, in step with %s",
+	Excluding:  "%s, except at the times excluded by a calendar",
+	Custom:     "on a custom schedule",
+}
+
+// Describe returns a description of the schedule in English, such as "At 09:30
+// on Monday through Friday" or "Every 90 minutes".
+func Describe(schedule Schedule) string {
+	return English.Describe(schedule)
+}
+
+// Describe returns a description of the schedule in English. See the Describe
+// function.
+func (s *SpecSchedule) Describe() string {
+	return English.Describe(s)
+}
+
+// Describe returns a description of the schedule in English, such as "Every 90
+// minutes".
+func (schedule ConstantDelaySchedule) Describe() string {
+	return English.Describe(schedule)
+}
+
+// Describe returns a description of the schedule made of the phrases. Schedules
+// of types other than those of this package are described as custom schedules.
+func (p *Phrases) Describe(schedule Schedule) string {
+	return capitalize(p.describe(schedule))
+}
+
+func (p *Phrases) describe(schedule Schedule) string {
+	switch s := schedule.(type) {
+	case *SpecSchedule:
+		return p.describeSpec(s)
+	case ConstantDelaySchedule:
+		if !s.Anchor.IsZero() {
+			return fmt.Sprintf(p.Anchored, p.describeDelay(s.Delay), s.Anchor.Format(p.OnceLayout))
+		}
+		return p.describeDelay(s.Delay)
+	case OnceSchedule:
+		return fmt.Sprintf(p.Once, s.Time.Format(p.OnceLayout))
+	case ExcludeSchedule:
+		return fmt.Sprintf(p.Excluding, p.describe(s.Schedule))
+	case Spec:
+		return p.describe(s.Schedule)
+	}
+	return p.Custom
+}
+
+// describeDelay describes an interval in the largest unit that divides it.
+func (p *Phrases) describeDelay(d time.Duration) string {
+	unit, n := p.Second, d/time.Second
+	switch {
+	case d%time.Hour == 0:
+		unit, n = p.Hour, d/time.Hour
+	case d%time.Minute == 0:
+		unit, n = p.Minute, d/time.Minute
+	}
+	if n == 1 {
+		return unit.Every
+	}
+	return fmt.Sprintf(unit.EveryN, n)
+}
+
+// describeSpec describes the time of day, days, months and years of a spec.
+func (p *Phrases) describeSpec(s *SpecSchedule) string {
+	parts := []string{p.describeTime(s)}
+	if days := p.describeDays(s); days != "" {
+		parts = append(parts, days)
+	}
+	if month := values(s.Month, months); len(month) < int(months.max-months.min+1) {
+		parts = append(parts, fmt.Sprintf(p.InMonths, p.runs(month, p.monthName)))
+	}
+	if year := yearValues(s.Year); len(year) > 0 {
+		parts = append(parts, p.describeYears(year))
+	}
+	if s.Location != nil {
+		parts = append(parts, fmt.Sprintf(p.Location, s.Location))
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeTime describes the time of day fields: as a list of times if there
+// are few, or else as the phrases of the fields, leaving out those implied by
+// the others.
+func (p *Phrases) describeTime(s *SpecSchedule) string {
+	second, minute, hour := values(s.Second, seconds), values(s.Minute, minutes), values(s.Hour, hours)
+	everyHour := len(hour) == int(hours.max-hours.min+1)
+	if len(second) == 1 && len(minute) == 1 && !everyHour && len(hour) <= 6 {
+		var times []string
+		for _, h := range hour {
+			t := fmt.Sprintf("%02d:%02d", h, minute[0])
+			if second[0] != 0 {
+				t += fmt.Sprintf(":%02d", second[0])
+			}
+			times = append(times, t)
+		}
+		return fmt.Sprintf(p.AtTimes, p.list(times))
+	}
+
+	var parts []string
+	zero := func(v []uint) bool { return len(v) == 1 && v[0] == 0 }
+	if !zero(second) {
+		parts = append(parts, p.describeUnit(second, seconds, p.Second))
+	}
+	if !(zero(minute) && zero(second)) && !(len(minute) == 60 && len(second) > 1) {
+		parts = append(parts, p.describeUnit(minute, minutes, p.Minute))
+	}
+	switch {
+	case everyHour:
+		if len(second) == 1 && len(minute) == 1 {
+			parts = append(parts, p.Hour.Every)
+		}
+	case len(hour) > 0 && hour[len(hour)-1]-hour[0] == uint(len(hour)-1):
+		// A single range of hours.
+		parts = append(parts, fmt.Sprintf(p.Between,
+			fmt.Sprintf("%02d:00", hour[0]), fmt.Sprintf("%02d:59", hour[len(hour)-1])))
+	default:
+		parts = append(parts, p.describeUnit(hour, hours, p.Hour))
+	}
+	phrase := parts[0]
+	for _, part := range parts[1:] {
+		phrase = fmt.Sprintf(p.Comma, phrase, part)
+	}
+	return phrase
+}
+
+// describeUnit describes the values of a field, in steps if they are evenly
+// spaced.
+func (p *Phrases) describeUnit(v []uint, r bounds, unit UnitPhrases) string {
+	if len(v) == int(r.max-r.min+1) {
+		return unit.Every
+	}
+	if step := progression(v); step > 1 {
+		if v[0] == r.min && v[len(v)-1]+step > r.max {
+			return fmt.Sprintf(unit.EveryN, step)
+		}
+		return fmt.Sprintf(unit.EveryNFrom, step, v[0], v[len(v)-1])
+	}
+	if len(v) == 1 {
+		return fmt.Sprintf(unit.At, p.runs(v, number))
+	}
+	return fmt.Sprintf(unit.AtList, p.runs(v, number))
+}
+
+// describeDays describes the days of month and of week, and how they combine,
+// or returns the empty string if the schedule runs every day.
+func (p *Phrases) describeDays(s *SpecSchedule) string {
+	monthDays, weekDays := values(s.Dom, dom), values(s.Dow, dow)
+	domAll := len(monthDays) == int(dom.max-dom.min+1) && s.DomLast == 0 && s.DomWeekday == 0
+	dowAll := len(weekDays) == int(dow.max-dow.min+1) && s.DowLast == 0 && s.DowNth == 0
+
+	// As in dayMatches, either field matches unless one of them has a star.
+	and := s.Dom&starBit > 0 || s.Dow&starBit > 0
+	switch {
+	case domAll && dowAll, !and && (domAll || dowAll):
+		return ""
+	case dowAll:
+		return p.describeMonthDays(s, monthDays)
+	case domAll:
+		return p.describeWeekDays(s, weekDays)
+	case and:
+		return fmt.Sprintf(p.DaysAnd, p.describeMonthDays(s, monthDays), p.describeWeekDays(s, weekDays))
+	}
+	return fmt.Sprintf(p.DaysOr, p.describeMonthDays(s, monthDays), p.describeWeekDays(s, weekDays))
+}
+
+// describeMonthDays describes the days of month, with the last days and nearest
+// weekdays.
+func (p *Phrases) describeMonthDays(s *SpecSchedule, days []uint) string {
+	if step := progression(days); step > 1 && s.DomLast == 0 && s.DomWeekday == 0 {
+		return p.describeUnit(days, dom, p.DayOfMonth)
+	}
+	var items []string
+	switch {
+	case len(days) == 1:
+		items = append(items, fmt.Sprintf(p.DayOfMonth.At, p.runs(days, number)))
+	case len(days) > 1:
+		items = append(items, fmt.Sprintf(p.DayOfMonth.AtList, p.runs(days, number)))
+	}
+	for n := uint(0); n < dom.max; n++ {
+		switch {
+		case s.DomLast&(1<<n) == 0:
+		case n == 0:
+			items = append(items, p.LastDay)
+		default:
+			items = append(items, fmt.Sprintf(p.DaysBeforeLastDay, n))
+		}
+	}
+	for n := uint(0); n <= dom.max; n++ {
+		switch {
+		case s.DomWeekday&(1<<n) == 0:
+		case n == 0:
+			items = append(items, p.LastWeekday)
+		default:
+			items = append(items, fmt.Sprintf(p.NearestWeekday, n))
+		}
+	}
+	return fmt.Sprintf(p.OnDaysOfMonth, p.list(items))
+}
+
+// describeWeekDays describes the days of week, with the last and nth days of
+// week of the month.
+func (p *Phrases) describeWeekDays(s *SpecSchedule, days []uint) string {
+	var items []string
+	if len(days) > 0 {
+		items = append(items, p.runs(days, p.weekdayName))
+	}
+	for w := uint(0); w <= dow.max; w++ {
+		if s.DowLast&(1<<w) > 0 {
+			items = append(items, fmt.Sprintf(p.LastOfMonth, p.Weekdays[w]))
+		}
+	}
+	for n := uint(0); n < 5; n++ {
+		for w := uint(0); w <= dow.max; w++ {
+			if s.DowNth&(1<<(7*n+w)) > 0 {
+				items = append(items, fmt.Sprintf(p.NthOfMonth, p.Ordinals[n], p.Weekdays[w]))
+			}
+		}
+	}
+	return fmt.Sprintf(p.OnWeekdays, p.list(items))
+}
+
+// describeYears describes the years, in steps if they are evenly spaced.
+func (p *Phrases) describeYears(v []uint) string {
+	if step := progression(v); step > 1 {
+		return fmt.Sprintf(p.EveryNYears, step, v[0], v[len(v)-1])
+	}
+	return fmt.Sprintf(p.InYears, p.runs(v, number))
+}
+
+// runs lists the values, collapsing runs of three or more consecutive values
+// into ranges.
+func (p *Phrases) runs(v []uint, name func(uint) string) string {
+	var items []string
+	for i := 0; i < len(v); {
+		j := i
+		for j+1 < len(v) && v[j+1] == v[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			items = append(items, fmt.Sprintf(p.Through, name(v[i]), name(v[j])))
+		case j-i == 1:
+			items = append(items, name(v[i]), name(v[j]))
+		default:
+			items = append(items, name(v[i]))
+		}
+		i = j + 1
+	}
+	return p.list(items)
+}
+
+// list joins the items, as in "a, b and c".
+func (p *Phrases) list(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	s := items[len(items)-1]
+	if len(items) > 1 {
+		s = fmt.Sprintf(p.And, items[len(items)-2], s)
+		for i := len(items) - 3; i >= 0; i-- {
+			s = fmt.Sprintf(p.Comma, items[i], s)
+		}
+	}
+	return s
+}
+
+func (p *Phrases) monthName(m uint) string   { return p.Months[m-1] }
+func (p *Phrases) weekdayName(w uint) string { return p.Weekdays[w] }
+
+func number(n uint) string { return strconv.Itoa(int(n)) }
+
+// values returns the values of the bits within the bounds, in order.
+func values(bits uint64, r bounds) []uint {
+	var v []uint
+	for i := r.min; i <= r.max; i++ {
+		if bits&(1<<i) > 0 {
+			v = append(v, i)
+		}
+	}
+	return v
+}
+
+// yearValues returns the years set in the year bits, in order.
+func yearValues(bits [3]uint64) []uint {
+	var v []uint
+	for i := uint(0); i <= years.max-years.min; i++ {
+		if bits[i/64]&(1<<(i%64)) > 0 {
+			v = append(v, years.min+i)
+		}
+	}
+	return v
+}
+
+// progression returns the step between the values, if there are at least three
+// of them and they are evenly spaced, or 0.
+func progression(v []uint) uint {
+	if len(v) < 3 {
+		return 0
+	}
+	step := v[1] - v[0]
+	for i := 2; i < len(v); i++ {
+		if v[i]-v[i-1] != step {
+			return 0
+		}
+	}
+	return step
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
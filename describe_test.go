@@ -0,0 +1,89 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	yearParser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year | Descriptor)
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"0 30 9 * * 1-5", "At 09:30 on Monday through Friday"},
+		{"@every 90m", "Every 90 minutes"},
+		{"@every 1h", "Every hour"},
+		{"@every 45s", "Every 45 seconds"},
+		{"* * * * * *", "Every second"},
+		{"0 * * * * *", "Every minute"},
+		{"@hourly", "Every hour"},
+		{"0 30 * * * *", "At minute 30, every hour"},
+		{"*/10 * * * * *", "Every 10 seconds"},
+		{"0 */15 9-17 * * MON-FRI", "Every 15 minutes, between 09:00 and 17:59 on Monday through Friday"},
+		{"0 * 9 * * *", "Every minute, between 09:00 and 09:59"},
+		{"0 0 */2 * * *", "Every 2 hours"},
+		{"0 30 */2 * * *", "At minute 30, every 2 hours"},
+		{"0 5-20/5 * * * *", "Every 5 minutes from minute 5 through 20"},
+		{"0 0,10,20,21,22,45 * * * *", "At minutes 0, 10, 20 through 22 and 45"},
+		{"15 30 9,17 * * *", "At 09:30:15 and 17:30:15"},
+		{"@yearly", "At 00:00 on day 1 of the month in January"},
+		{"0 0 12 1,15 */3 *", "At 12:00 on days 1 and 15 of the month in January, April, July and October"},
+		{"0 0 12 */2 * *", "At 12:00 every 2 days of the month"},
+		{"0 0 18 L * ?", "At 18:00 on the last day of the month"},
+		{"0 0 18 1,L-2,LW,15W * ?", "At 18:00 on day 1, 2 days before the last day, the last weekday and the weekday nearest day 15 of the month"},
+		{"0 0 9 ? * MON#2,5L", "At 09:00 on the last Friday of the month and the second Monday of the month"},
+		{"0 0 9 13 * FRI", "At 09:00 on day 13 of the month, or on Friday"},
+		{"0 0 9 */2 * MON", "At 09:00 every 2 days of the month, but only on Monday"},
+		{"TZ=Europe/Paris 0 0 9 * * *", "At 09:00 (Europe/Paris)"},
+		{"0 0 12 1 1 ? 2027-2029", "At 12:00 on day 1 of the month in January in 2027 through 2029"},
+		{"0 0 12 1 1 ? 2028/4", "At 12:00 on day 1 of the month in January every 4 years from 2028 through 2096"},
+	}
+	for _, c := range tests {
+		schedule, err := yearParser.Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		if actual := Describe(schedule); actual != c.expected {
+			t.Errorf("%s: (expected) %q != %q (actual)", c.spec, c.expected, actual)
+		}
+	}
+}
+
+func TestDescribeOther(t *testing.T) {
+	at := time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		schedule Schedule
+		expected string
+	}{
+		{At(at), "Once, at 2026-12-01 09:00:00 UTC"},
+		{Exclude(Every(time.Hour), NewCalendar()), "Every hour, except at the times excluded by a calendar"},
+		{customSchedule{}, "On a custom schedule"},
+		{Spec{"@every 90m", Every(90 * time.Minute)}, "Every 90 minutes"},
+		{EveryFrom(at, 90*time.Minute), "Every 90 minutes, in step with 2026-12-01 09:00:00 UTC"},
+	}
+	for _, c := range tests {
+		if actual := Describe(c.schedule); actual != c.expected {
+			t.Errorf("(expected) %q != %q (actual)", c.expected, actual)
+		}
+	}
+}
+
+func TestDescribePhrases(t *testing.T) {
+	french := *English
+	french.And = "%s et %s"
+	french.Through = "%s à %s"
+	french.AtTimes = "à %s"
+	french.OnWeekdays = "le %s"
+	french.Weekdays = [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"}
+
+	schedule, _ := Parse("0 30 9 * * 1-5")
+	if actual, expected := french.Describe(schedule), "À 09:30 le lundi à vendredi"; actual != expected {
+		t.Errorf("(expected) %q != %q (actual)", expected, actual)
+	}
+}
+
+type customSchedule struct{}
+
+func (customSchedule) Next(t time.Time) time.Time { return t.Add(time.Hour) }
@@ -28,16 +28,27 @@ A cron expression represents a set of times, using 6 space-separated fields.
 
 	Field name   | Mandatory? | Allowed values  | Allowed special characters
 	----------   | ---------- | --------------  | --------------------------
-	Seconds      | Yes        | 0-59            | * / , -
-	Minutes      | Yes        | 0-59            | * / , -
-	Hours        | Yes        | 0-23            | * / , -
-	Day of month | Yes        | 1-31            | * / , - ?
-	Month        | Yes        | 1-12 or JAN-DEC | * / , -
-	Day of week  | Yes        | 0-6 or SUN-SAT  | * / , - ?
+	Seconds      | Yes        | 0-59            | * / , - H
+	Minutes      | Yes        | 0-59            | * / , - H
+	Hours        | Yes        | 0-23            | * / , - H
+	Day of month | Yes        | 1-31            | * / , - ? H L W
+	Month        | Yes        | 1-12 or JAN-DEC | * / , - H
+	Day of week  | Yes        | 0-6 or SUN-SAT  | * / , - ? H L #
 
 Note: Month and Day-of-week field values are case insensitive.  "SUN", "Sun",
 and "sun" are equally accepted.
 
+Parsers created with the Year option, such as
+NewParser(Second | Minute | Hour | Dom | Month | Dow | Year), also accept an
+optional seventh field, as Quartz does:
+
+	Field name   | Mandatory? | Allowed values  | Allowed special characters
+	----------   | ---------- | --------------  | --------------------------
+	Year         | No         | 1970-2099       | * / , -
+
+For example, "0 0 12 1 Jan ? 2027" would run only once, at noon on New Year's
+Day 2027. Schedules limited to some years do not activate after them.
+
 Special Characters
 
 Asterisk ( * )
@@ -70,6 +81,26 @@ Question mark ( ? )
 Question mark may be used instead of '*' for leaving either day-of-month or
 day-of-week blank.
 
+Hash ( H )
+
+H stands for a value chosen by a stable hash of a key, such as the name of the
+job, to spread jobs with the same spec over time without random drift. For
+example, "0 H * * * *" would run once an hour, at a minute that depends on the
+key. H may be limited to a range, as in H(0-29), and given a step, as in H/15,
+which would run every 15 minutes starting from a minute chosen by the hash. In
+the day of month field, H stands for a day from 1 to 28. Set the key with
+Parser.WithHashKey; Cron.AddFunc and AddJob use the entry's name.
+
+L, W and #
+
+In the day of month field, L stands for the last day of the month, and L-n for
+the nth-to-last day. nW stands for the weekday (Monday to Friday) nearest to day
+n of the month, without leaving the month, and LW for the last weekday of the
+month. In the day of week field, dL stands for the last day d of the month, as
+in 5L or FRIL for the last Friday, and d#n for the nth day d of the month, as in
+MON#2 for the second Monday. For example, "0 0 18 LW * ?" would run at 6pm on
+the last weekday of every month.
+
 Predefined schedules
 
 You may use one of several pre-defined schedules in place of a cron expression.
@@ -82,6 +113,118 @@ You may use one of several pre-defined schedules in place of a cron expression.
 	@daily (or @midnight)  | Run once a day, midnight                   | 0 0 0 * * *
 	@hourly                | Run once an hour, beginning of hour        | 0 0 * * * *
 
+Parse errors
+
+The errors returned for invalid specs are of type *ParseError, which tells
+where the error is, so that it may be highlighted in a user interface, and why,
+as a machine-readable Reason such as ReasonAboveMaximum:
+
+	_, err := cron.Parse("0 0 12 1-40 * *")
+	var perr *cron.ParseError
+	if errors.As(err, &perr) {
+		// perr.Field == "dom", perr.Index == 3, perr.Token == "1-40",
+		// perr.Start == 7, perr.End == 11
+	}
+
+Lint
+
+Some specs are valid, but rarely do what was meant. Lint (or Parser.Lint)
+returns warnings about them, with an explanation and, where there is an obvious
+one, a suggested fix. Lint reads specs as Parse does, with a seconds field; use
+the Lint method of a Parser for specs in another format:
+
+	"0 * 5 * * *"      runs every minute from 05:00 to 05:59, not once at 05:00
+	                   (suggests "0 0 5 * * *")
+	"0 0-59/7 * * * *" runs at minutes 0, 7, ..., 56 and then 0 again, 4 minutes
+	                   later (suggests "@every 7m")
+	"0 0 9 1-7 * MON"  runs on the first seven days of the month and on every
+	                   Monday, since the day fields match if either does
+	                   (suggests "0 0 9 ? * MON#1")
+
+Canonical specs
+
+Schedules print as the shortest canonical spec that parses back to them, with
+lists turned into ranges and steps, and descriptors where they match:
+
+	s, _ := cron.Parse("0 0,15,30,45 9-17 * * mon,tue,wed,thu,fri")
+	s.(*cron.SpecSchedule).String()        // "0 0/15 9-17 * * 1-5"
+	cron.Every(90 * time.Minute).String() // "@every 1h30m0s"
+
+Schedules in configuration files
+
+The built-in schedules implement encoding.TextMarshaler and
+encoding.TextUnmarshaler, so they may be decoded from JSON, YAML and other
+formats, and checked while they are. Spec holds any schedule together with the
+spec as it was written:
+
+	var config struct {
+		Schedule cron.Spec `json:"schedule"`
+	}
+	err := json.Unmarshal([]byte(`{"schedule": "0 30 9 * * 1-5"}`), &config)
+	..
+	c.Schedule(config.Schedule, job)
+
+Comparing schedules
+
+Equal reports whether two schedules activate at the same times, however they
+were spelled: "0 0 * * * *" and "@hourly" are equal. Each built-in schedule also
+has a Fingerprint, a hash that is the same for equal schedules and stable across
+processes, to detect duplicate jobs or tell real changes from cosmetic ones when
+reloading a configuration. A Spec compares, and is described, as the schedule it
+holds.
+
+A star only matters in the hour field, where it changes how repeated times are
+handled when the clocks go back, and in the day fields, where it makes them
+combine with AND, whichever of them has it.
+
+Descriptions
+
+Describe describes a schedule in English, for readers who do not read cron
+specs:
+
+	s, _ := cron.Parse("0 30 9 * * 1-5")
+	cron.Describe(s)                             // "At 09:30 on Monday through Friday"
+	cron.Describe(cron.Every(90 * time.Minute)) // "Every 90 minutes"
+
+The words are taken from a Phrases table. To describe schedules in another
+language, copy English, translate its phrases and call its Describe method.
+
+Previous activations
+
+Schedules that implement PrevScheduler can also tell when they last activated,
+before a given time, for example to decide whether a job missed its last run
+while the program was not running:
+
+	if p, ok := schedule.(cron.PrevScheduler); ok && p.Prev(time.Now()).After(lastRun) {
+		job.Run()
+	}
+
+Cron specs walk back through their fields, handling daylight saving time
+transitions as Next does, so that Next finds no activation between Prev(t) and
+t. Intervals activate one delay after any time they are asked about, so they
+only tell their previous activation when anchored to a time with
+EveryFrom(anchor, delay); they then activate at the anchor and every delay
+before and after it.
+
+Listing activations
+
+NextN and Between list the activation times of any schedule, and Times iterates
+over them:
+
+	cron.NextN(schedule, time.Now(), 10)     // The next 10 runs
+	cron.Between(schedule, monday, saturday) // The runs until the weekend
+	for t := range cron.Times(schedule, time.Now()) {
+		..
+	}
+
+They end when the schedule does, or when its Next method fails to move forward,
+so that a faulty custom schedule cannot make them loop forever.
+
+Schedules that implement Matcher tell whether they activate at a given time
+without searching for it. Cron specs match the wall clock time against each
+field, so at daylight saving time transitions they match skipped and repeated
+times that Next would move or leave out.
+
 Intervals
 
 You may also schedule a job to execute at fixed intervals, starting at the time it's added 
@@ -95,17 +238,151 @@ where "duration" is a string accepted by time.ParseDuration
 For example, "@every 1h30m10s" would indicate a schedule that activates immediately,
 and then every 1 hour, 30 minutes, 10 seconds.
 
+An interval may be anchored to a time, in RFC 3339 format, so that it activates
+at that time and every interval before and after it, whenever the job was
+added: "@every 24h from 2026-12-01T09:00:00Z", or EveryFrom(anchor, delay). The
+anchor is kept in the spec the schedule prints as, and in its description.
+
 Note: The interval does not take the job runtime into account.  For example,
 if a job takes 3 minutes to run, and it is scheduled to run every 5 minutes,
 it will have only 2 minutes of idle time between each run.
 
+One-shot schedules
+
+A job may also be run just once, at a given time. This is supported by
+formatting the cron spec like this:
+
+    @at <time>
+
+where "time" is a string in RFC 3339 format, e.g. "@at 2026-12-01T09:00:00Z", or
+by scheduling the job with cron.At(t). Once the job has run, its entry is
+removed from the Cron.
+
+Validity windows and run limits
+
+Entries may be limited to a window of time, or to a number of runs, by passing
+options when they are added:
+
+	c.AddFunc("@every 10m", refresh,
+		cron.WithStart(launch), cron.WithEnd(campaignEnd), cron.WithMaxRuns(100))
+
+The job does not run before the start time, and the entry is removed from the
+Cron once its end time has passed or it has run the given number of times. This
+holds for runs triggered by dependencies as well as by the schedule.
+Entries that would never run, such as one-shot schedules in the past or windows
+that have already ended, are refused: AddFunc returns an error for them, and
+Schedule logs it. Schedule keeps its signature, with no return value, so that
+existing callers still compile; ScheduleEntry returns the error instead.
+
+Jobs normally run for the first time when their schedule next activates. To run
+a job once right away when the Cron starts (or when it is added to a running
+Cron), and then follow its schedule, pass cron.WithRunOnStart(true). Setting the
+Cron's RunOnStart field makes this the default for entries added afterwards.
+
+To spread the load of jobs that share a schedule, such as @hourly, each run of
+an entry may be delayed by a random duration of up to a maximum:
+
+	c.AddFunc("@hourly", job, cron.WithJitter(5*time.Minute))
+
+Entry.Next includes the delay, but the schedule does not drift: each run follows
+on from the previous activation of the schedule. Set the Cron's Rand field to
+seed the delays, such as in tests.
+
+Job dependencies
+
+Jobs may be triggered by the completion of other jobs instead of by a schedule.
+Each entry is identified by the EntryID returned when it is added with
+AddFuncEntry, AddJobEntry or ScheduleEntry:
+
+	extract, _ := c.AddFuncEntry("@daily", extractData)
+	transform, _ := c.AddDependent(cron.FuncJob(transformData), cron.OnSuccess, extract)
+	load, _ := c.AddDependent(cron.FuncJob(loadData), cron.OnSuccess, extract)
+	c.AddDependent(cron.FuncJob(publish), cron.OnSuccess, transform, load)
+
+A run succeeds if the job returns without panicking. Dependents on
+cron.OnCompletion run after failed runs, too. A job with several upstream entries
+runs once all of them have completed since it last ran, and records the upstream
+executions that triggered it in Entry.TriggeredBy. Dependencies may also be added
+between existing entries with AddDependency, which refuses to create cycles.
+
+Entries are removed with Remove. Dependents that are left with no upstream entry
+are removed along with it, since nothing could trigger them.
+
+Exclusion calendars
+
+Any schedule may be wrapped so that it skips the times excluded by a Calendar,
+such as public holidays:
+
+	holidays, err := cron.LoadCalendar("holidays.txt")
+	..
+	weekdays, _ := cron.Parse("0 0 9 * * MON-FRI")
+	c.Schedule(cron.Exclude(weekdays, holidays), job)
+
+MemoryCalendar holds excluded dates and periods in memory. See ReadCalendar for
+the file format read by LoadCalendar.
+
+Calendars may also be read from iCalendar (.ics) files with LoadICalendar. The
+resulting ICalendar excludes the times during its events, including recurring
+ones, and may also be used as a Schedule that activates at the start of each
+event:
+
+	holidays, err := cron.LoadICalendar("holidays.ics", time.Local)
+	..
+	c.Schedule(cron.Exclude(weekdays, holidays), job)
+	c.Schedule(holidays, greeting)
+
+Calendar feed
+
+The upcoming runs of a Cron's entries may be published as an iCalendar feed, so
+that people can see in their calendar applications when jobs will run. Name the
+entries to identify them:
+
+	c.AddFunc("0 0 2 * * *", batch, cron.WithName("Nightly batch"))
+	http.Handle("/cron.ics", c.ICalendarHandler(20))
+
+This serves one event for each of the next 20 runs of every entry, described by
+the entry's spec. WriteICalendar writes the same document to an io.Writer.
+
+Blackouts
+
+The Cron may be kept from starting any jobs during blackouts, such as a
+maintenance window. Any Calendar may give the blackout times: a MemoryCalendar
+for one-off periods, or Windows for a recurring schedule and a duration:
+
+	sundays, _ := cron.Parse("0 0 3 * * SUN")
+	c.AddBlackout(cron.Windows(sundays, time.Hour), cron.SkipRun)
+
+Runs that are due during a blackout are either skipped (cron.SkipRun), or
+deferred to the end of the blackout (cron.DeferRun). Set the Cron's OnBlackout
+func to be told about each of them.
+
 Time zones
 
 All interpretation and scheduling is done in the machine's local time zone (as
-provided by the Go time package (http://www.golang.org/pkg/time).
+provided by the Go time package (http://www.golang.org/pkg/time), or in the
+location given to NewWithLocation.
+
+Individual cron schedules may override the time zone they are interpreted in by
+prefixing the spec with "CRON_TZ=" (or "TZ=") and a time zone name from the IANA
+database:
+
+	// Runs at 6am in time.Local
+	cron.New().AddFunc("0 0 6 * * ?", ...)
 
-Be aware that jobs scheduled during daylight-savings leap-ahead transitions will
-not be run!
+	// Runs at 6am in America/New_York, regardless of the Cron's location
+	cron.New().AddFunc("CRON_TZ=America/New_York 0 0 6 * * ?", ...)
+
+The time zone of each entry is reported by Entry.Location.
+
+Daylight saving time transitions are handled the way Vixie cron handles them:
+
+ - Jobs scheduled during a skipped interval (the clocks go forward) run once,
+   right after the gap. A job at 02:30 runs at 03:00 on that day.
+ - Jobs scheduled at a fixed hour during a repeated interval (the clocks go
+   back) run once, during the first occurrence.
+ - Jobs with a wildcard hour ("*" or "*\/n") follow the hours as they pass:
+   they do not run during a skipped interval, and run during both occurrences
+   of a repeated interval.
 
 Thread safety
 
@@ -125,5 +402,12 @@ Upon waking:
  - it calculates the next run times for the jobs that were run
  - it re-sorts the array of entries by next activation time.
  - it goes to sleep until the soonest job.
+
+The next activation time of a cron spec is found by jumping from one matching
+value of each field to the next, using the bit sets that the spec is parsed
+into. Since the calendar repeats itself every 400 years, a spec that does not
+activate within that time never does: Next returns the zero time for it, and
+SpecSchedule.Satisfiable reports it. The parser rejects such specs, like
+"0 0 0 30 Feb ?", with an error. NextWithin limits the search to fewer years.
 */
 package cron
@@ -0,0 +1,164 @@
+package cron
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// canonicalSpec holds what determines when a SpecSchedule activates, so that
+// schedules that activate at the same times compare equal however they were
+// spelled.
+type canonicalSpec struct {
+	second, minute, hour, dom, month, dow uint64
+	domLast, domWeekday, dowLast, dowNth  uint64
+	year                                  [3]uint64
+
+	// Whether a day must match both the days of month and of week (as when
+	// either field has a star), rather than either of them.
+	and bool
+
+	location string
+}
+
+// canonical returns the canonical form of the schedule. The star bit only
+// matters in the hour field, where it changes how repeated times are handled
+// when the clocks go back, and in the day fields, where it makes them combine
+// with AND rather than OR: "0 0 * * * *" and "@hourly" are equal, and so are
+// "0 0 0 */2 * 0" and "0 0 0 1/2 * */7".
+func (s *SpecSchedule) canonical() canonicalSpec {
+	c := canonicalSpec{
+		second:     s.Second & getBits(seconds.min, seconds.max, 1),
+		minute:     s.Minute & getBits(minutes.min, minutes.max, 1),
+		hour:       s.Hour & (getBits(hours.min, hours.max, 1) | starBit),
+		dom:        s.Dom & getBits(dom.min, dom.max, 1),
+		month:      s.Month & getBits(months.min, months.max, 1),
+		dow:        s.Dow & getBits(dow.min, dow.max, 1),
+		domLast:    s.DomLast,
+		domWeekday: s.DomWeekday,
+		dowLast:    s.DowLast,
+		dowNth:     s.DowNth,
+		year:       s.Year,
+		and:        s.Dom&starBit > 0 || s.Dow&starBit > 0,
+	}
+	if c.year == allYears() {
+		c.year = [3]uint64{}
+	}
+	if s.Location != nil {
+		c.location = s.Location.String()
+	}
+
+	// A field that matches every day leaves the days to the other field if
+	// they combine with AND, and matches every day if they combine with OR.
+	domAll := c.dom == getBits(dom.min, dom.max, 1) && c.domLast == 0 && c.domWeekday == 0
+	dowAll := c.dow == getBits(dow.min, dow.max, 1) && c.dowLast == 0 && c.dowNth == 0
+	if domAll && dowAll || !c.and && (domAll || dowAll) {
+		c.dom, c.dow = getBits(dom.min, dom.max, 1), getBits(dow.min, dow.max, 1)
+		c.domLast, c.domWeekday, c.dowLast, c.dowNth = 0, 0, 0, 0
+		c.and = true
+	}
+	return c
+}
+
+// allYears returns the year bits with every year set.
+func allYears() [3]uint64 {
+	var bits [3]uint64
+	for i := uint(0); i <= years.max-years.min; i++ {
+		bits[i/64] |= 1 << (i % 64)
+	}
+	return bits
+}
+
+// Equal reports whether the schedules activate at the same times, even if they
+// were given by different specs, such as "0 0 * * * *" and "@hourly". The
+// locations are compared by name.
+func (s *SpecSchedule) Equal(o *SpecSchedule) bool {
+	return s.canonical() == o.canonical()
+}
+
+// Fingerprint returns a hash of the schedule that is the same for equal
+// schedules, and stable across processes and versions of this package.
+func (s *SpecSchedule) Fingerprint() uint64 {
+	c := s.canonical()
+	return fingerprint("spec", fmt.Sprintf("%x %x %x %x %x %x %x %x %x %x %x %t %s",
+		c.second, c.minute, c.hour, c.dom, c.month, c.dow,
+		c.domLast, c.domWeekday, c.dowLast, c.dowNth, c.year, c.and, c.location))
+}
+
+// Equal reports whether the schedules have the same delay and, if anchored,
+// activate at the same times: anchors a whole number of delays apart are equal.
+func (schedule ConstantDelaySchedule) Equal(o ConstantDelaySchedule) bool {
+	return schedule.Delay == o.Delay && schedule.Anchor.IsZero() == o.Anchor.IsZero() &&
+		schedule.phase() == o.phase()
+}
+
+// phase returns the offset of the activations of an anchored schedule from the
+// last one before the Unix epoch.
+func (schedule ConstantDelaySchedule) phase() time.Duration {
+	if schedule.Anchor.IsZero() {
+		return 0
+	}
+	d := schedule.Anchor.Sub(time.Unix(0, 0))
+	return d - schedule.periods(d)
+}
+
+// Fingerprint returns a hash of the schedule that is the same for equal
+// schedules, and stable across processes and versions of this package.
+func (schedule ConstantDelaySchedule) Fingerprint() uint64 {
+	if schedule.Anchor.IsZero() {
+		return fingerprint("every", schedule.Delay.String())
+	}
+	return fingerprint("every", schedule.Delay.String()+" "+schedule.phase().String())
+}
+
+// Equal reports whether the schedules activate at the same instant.
+func (schedule OnceSchedule) Equal(o OnceSchedule) bool {
+	return schedule.Time.Equal(o.Time)
+}
+
+// Fingerprint returns a hash of the schedule that is the same for equal
+// schedules, and stable across processes and versions of this package.
+func (schedule OnceSchedule) Fingerprint() uint64 {
+	return fingerprint("at", schedule.Time.UTC().Format(time.RFC3339))
+}
+
+// Equal reports whether the schedules are of the same built-in type and
+// activate at the same times. Schedules of other types are never equal, nor
+// are those that exclude the times of a calendar. The schedules held by Specs
+// are compared, whatever their text.
+func Equal(a, b Schedule) bool {
+	b = unwrapSpec(b)
+	switch a := unwrapSpec(a).(type) {
+	case *SpecSchedule:
+		b, ok := b.(*SpecSchedule)
+		return ok && a.Equal(b)
+	case ConstantDelaySchedule:
+		b, ok := b.(ConstantDelaySchedule)
+		return ok && a.Equal(b)
+	case OnceSchedule:
+		b, ok := b.(OnceSchedule)
+		return ok && a.Equal(b)
+	}
+	return false
+}
+
+// Fingerprint returns the fingerprint of a schedule of a built-in type, as for
+// Equal, and whether it has one.
+func Fingerprint(schedule Schedule) (uint64, bool) {
+	switch s := unwrapSpec(schedule).(type) {
+	case *SpecSchedule:
+		return s.Fingerprint(), true
+	case ConstantDelaySchedule:
+		return s.Fingerprint(), true
+	case OnceSchedule:
+		return s.Fingerprint(), true
+	}
+	return 0, false
+}
+
+// fingerprint hashes the kind of schedule and its canonical value.
+func fingerprint(kind, value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(kind + ":" + value))
+	return h.Sum64()
+}
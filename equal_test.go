@@ -0,0 +1,82 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpecEqual(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"0 0 * * * *", "@hourly", true},
+		{"0 0 0 * * ?", "@midnight", true},
+		{"0 0,15,30,45 * * * *", "0 */15 * * * *", true},
+		{"0 30 9 * * mon-fri", "0 30 9 ? * 1,2,3,4,5", true},
+		{"0 0 0 1-31 * *", "0 0 0 * * *", true},
+		{"0 0 0 1 * 0-6", "@daily", true},
+		{"0 0 0 1 JAN *", "@yearly", true},
+		{"0 0 0 */2 * 0", "0 0 0 1/2 * */7", true},
+		{"TZ=UTC 0 0 9 * * *", "CRON_TZ=UTC 0 0 9 * * *", true},
+
+		{"0 0 * * * *", "0 0 0-23 * * *", false},
+		{"0 0 0 1/2 * 0", "0 0 0 */2 * 0", false},
+		{"0 0 0 L * *", "0 0 0 31 * *", false},
+		{"0 0 9 * * *", "TZ=UTC 0 0 9 * * *", false},
+		{"0 0 9 * * *", "0 0 10 * * *", false},
+	}
+	for _, c := range tests {
+		a, b := mustParse(t, c.a).(*SpecSchedule), mustParse(t, c.b).(*SpecSchedule)
+		if a.Equal(b) != c.equal || b.Equal(a) != c.equal || Equal(a, b) != c.equal {
+			t.Errorf("%s, %s: expected equal %v", c.a, c.b, c.equal)
+		}
+		if (a.Fingerprint() == b.Fingerprint()) != c.equal {
+			t.Errorf("%s, %s: expected the same fingerprint %v", c.a, c.b, c.equal)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	at := time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		a, b  Schedule
+		equal bool
+	}{
+		{Every(90 * time.Minute), mustParse(t, "@every 1h30m"), true},
+		{Every(time.Hour), mustParse(t, "@hourly"), false},
+		{At(at), At(at.In(time.FixedZone("CET", 3600))), true},
+		{At(at), At(at.Add(time.Second)), false},
+		{Exclude(Every(time.Hour), NewCalendar()), Exclude(Every(time.Hour), NewCalendar()), false},
+		{Spec{"@hourly", mustParse(t, "@hourly")}, mustParse(t, "0 0 * * * *"), true},
+		{Spec{"@every 1h", Every(time.Hour)}, Spec{"@every 60m", Every(time.Hour)}, true},
+		{Spec{"@hourly", mustParse(t, "@hourly")}, Every(time.Hour), false},
+		{EveryFrom(at, time.Hour), EveryFrom(at.Add(-49*time.Hour), time.Hour), true},
+		{EveryFrom(at, time.Hour), EveryFrom(at.Add(time.Minute), time.Hour), false},
+		{EveryFrom(at, time.Hour), Every(time.Hour), false},
+	}
+	for i, c := range tests {
+		if Equal(c.a, c.b) != c.equal {
+			t.Errorf("%d: expected equal %v", i, c.equal)
+		}
+		fa, oka := Fingerprint(c.a)
+		fb, okb := Fingerprint(c.b)
+		if c.equal && (!oka || !okb || fa != fb) {
+			t.Errorf("%d: expected the same fingerprint", i)
+		}
+	}
+	if _, ok := Fingerprint(customSchedule{}); ok {
+		t.Error("expected no fingerprint for a custom schedule")
+	}
+}
+
+// Test that fingerprints do not change, so that they may be stored.
+func TestFingerprintStable(t *testing.T) {
+	s := mustParse(t, "@hourly").(*SpecSchedule)
+	if s.Fingerprint() != mustParse(t, "0 0 * * * *").(*SpecSchedule).Fingerprint() {
+		t.Error("expected equal schedules to have the same fingerprint")
+	}
+	if fp := Every(time.Minute).Fingerprint(); fp != fingerprint("every", "1m0s") {
+		t.Errorf("unexpected fingerprint %x", fp)
+	}
+}
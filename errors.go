@@ -0,0 +1,107 @@
+package cron
+
+import (
+	"fmt"
+)
+
+// ParseReason is a machine-readable code for the reason a spec failed to parse.
+type ParseReason string
+
+const (
+	ReasonEmpty         ParseReason = "empty"          // The spec is empty
+	ReasonLocation      ParseReason = "location"       // The time zone is unknown, or not followed by fields
+	ReasonFieldCount    ParseReason = "field-count"    // The spec has too few or too many fields
+	ReasonSyntax        ParseReason = "syntax"         // An expression is malformed, such as "1--2"
+	ReasonNotANumber    ParseReason = "not-a-number"   // A value is neither a number nor a known name
+	ReasonNegative      ParseReason = "negative"       // A value is negative
+	ReasonBelowMinimum  ParseReason = "below-minimum"  // A value is below the minimum of its field
+	ReasonAboveMaximum  ParseReason = "above-maximum"  // A value is above the maximum of its field
+	ReasonReversedRange ParseReason = "reversed-range" // A range begins after it ends
+	ReasonZeroStep      ParseReason = "zero-step"      // A range has a step of zero
+	ReasonDescriptor    ParseReason = "descriptor"     // The descriptor is unknown
+	ReasonDuration      ParseReason = "duration"       // The duration of an @every descriptor is malformed
+	ReasonTime          ParseReason = "time"           // The time of an @at descriptor, or of the anchor of an @every descriptor, is malformed
+	ReasonUnsatisfiable ParseReason = "unsatisfiable"  // The spec never activates
+)
+
+// fieldNames are the names of the fields, in the order of places.
+var fieldNames = []string{"second", "minute", "hour", "dom", "month", "dow", "year"}
+
+// ParseError describes a spec that failed to parse: why, and where. The errors
+// returned by Parse and Parser.Parse are of this type, and may be inspected with
+// errors.As:
+//
+//	var perr *cron.ParseError
+//	if errors.As(err, &perr) {
+//		highlight(perr.Start, perr.End)
+//	}
+type ParseError struct {
+	// The spec, as given to the parser.
+	Spec string
+
+	// The name of the field that the error is in: "second", "minute", "hour",
+	// "dom", "month", "dow" or "year". It is empty for errors that are not
+	// within a field, such as a wrong number of fields.
+	Field string
+
+	// The position of the field among the fields of the spec, starting from
+	// 0, or -1 for errors that are not within a field.
+	Index int
+
+	// The offending part of the spec, and its byte offsets within the spec.
+	Token      string
+	Start, End int
+
+	// Why the spec failed to parse.
+	Reason ParseReason
+
+	// The underlying error, if any, such as from strconv or time.
+	Err error
+
+	msg string
+}
+
+// parseErrorf returns a ParseError for the given token, at the start of the
+// part of the spec being parsed.
+func parseErrorf(reason ParseReason, token string, format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Index:  -1,
+		Token:  token,
+		End:    len(token),
+		Reason: reason,
+		msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *ParseError) Error() string {
+	return e.msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// shift moves the offsets of a ParseError found within a part of the spec by
+// the offset of that part. Other errors are returned as they are.
+func shift(err error, offset int) error {
+	if perr, ok := err.(*ParseError); ok {
+		perr.Start += offset
+		perr.End += offset
+	}
+	return err
+}
+
+// inSpec completes a ParseError found within the part of spec at offset, in the
+// field in the given place (or -1 for none) and at the given index.
+func inSpec(err error, spec string, offset, place, index int) error {
+	perr, ok := shift(err, offset).(*ParseError)
+	if !ok {
+		return err
+	}
+	perr.Spec = spec
+	if place >= 0 {
+		perr.Field = fieldNames[place]
+		perr.Index = index
+	}
+	return perr
+}
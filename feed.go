@@ -0,0 +1,122 @@
+package cron
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalUTC is the format of a DATE-TIME value in UTC.
+const icalUTC = "20060102T150405Z"
+
+// WriteICalendar writes the next n runs of each of the entries after now to w,
+// as an iCalendar (.ics) document with one event per run. Each event is named
+// after its entry (or its ID, for entries without a name), and described by
+// the spec of its schedule. Entries that are only triggered by their
+// dependencies have no upcoming runs.
+func WriteICalendar(w io.Writer, entries []*Entry, now time.Time, n int) error {
+	iw := &icalWriter{w: w}
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//robfig//cron//EN")
+	stamp := now.UTC().Format(icalUTC)
+	for _, e := range entries {
+		summary := e.Name
+		if summary == "" {
+			summary = fmt.Sprintf("Entry %d", e.ID)
+		}
+		for _, run := range e.upcoming(now, n) {
+			start := run.UTC().Format(icalUTC)
+			iw.line("BEGIN", "VEVENT")
+			iw.line("UID", fmt.Sprintf("%d-%s@cron", e.ID, start))
+			iw.line("DTSTAMP", stamp)
+			iw.line("DTSTART", start)
+			iw.line("SUMMARY", escapeText(summary))
+			if e.Spec != "" {
+				iw.line("DESCRIPTION", escapeText(e.Spec))
+			}
+			iw.line("END", "VEVENT")
+		}
+	}
+	iw.line("END", "VCALENDAR")
+	return iw.err
+}
+
+// WriteICalendar writes the next n runs of each of the Cron's entries to w. See
+// the WriteICalendar function for the format.
+func (c *Cron) WriteICalendar(w io.Writer, n int) error {
+	return WriteICalendar(w, c.Entries(), c.now(), n)
+}
+
+// ICalendarHandler returns an http.Handler that serves the next n runs of each
+// of the Cron's entries as an iCalendar feed, to which calendar applications may
+// subscribe.
+func (c *Cron) ICalendarHandler(n int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := c.WriteICalendar(&buf, n); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		buf.WriteTo(w)
+	})
+}
+
+// upcoming returns the next n times at which the entry will run after now,
+// taking its validity window and run limit into account. Jitter is only known
+// for the next run. The entry itself is not modified.
+func (e *Entry) upcoming(now time.Time, n int) []time.Time {
+	entry := *e
+	entry.Jitter = 0
+	next := entry.Next
+	if next.IsZero() {
+		next = entry.next(now)
+	}
+	var times []time.Time
+	for len(times) < n && !next.IsZero() {
+		times = append(times, next)
+		entry.Runs++
+		next = entry.next(next)
+	}
+	return times
+}
+
+// icalWriter writes content lines, folding them at 75 octets, and keeps the
+// first error encountered.
+type icalWriter struct {
+	w   io.Writer
+	err error
+}
+
+// line writes a content line with the given name and (escaped) value.
+func (iw *icalWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	line := name + ":" + value
+	// Continuation lines have room for one octet less, after the leading space.
+	for width := 75; len(line) > width; width = 74 {
+		// Fold at the last rune boundary that fits.
+		i := width
+		for !utf8.RuneStart(line[i]) {
+			i--
+		}
+		buf.WriteString(line[:i])
+		buf.WriteString("\r\n ")
+		line = line[i:]
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+	_, iw.err = buf.WriteTo(iw.w)
+}
+
+// escapeText escapes a TEXT value.
+func escapeText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
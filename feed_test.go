@@ -0,0 +1,109 @@
+package cron
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICalendar(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 12, 30, 0, 0, time.UTC)
+	entries := []*Entry{
+		{ID: 1, Name: "Nightly, heavy batch", Spec: "0 0 2,14 * * *", Schedule: mustParse(t, "0 0 2,14 * * *")},
+		{ID: 2, Spec: "@every 1h", Schedule: mustParse(t, "@every 1h"), MaxRuns: 2, Runs: 1},
+		{ID: 3, Name: "Dependent"},
+	}
+	var buf bytes.Buffer
+	if err := WriteICalendar(&buf, entries, now, 3); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"SUMMARY:Nightly\\, heavy batch\r\n",
+		"DESCRIPTION:0 0 2\\,14 * * *\r\n",
+		"UID:1-20260301T140000Z@cron\r\n",
+		"DTSTAMP:20260301T123000Z\r\n",
+		"SUMMARY:Entry 2\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in:\n%s", expected, out)
+		}
+	}
+
+	cal, err := ReadICalendar(strings.NewReader(out), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expecteds := []struct {
+		summary string
+		start   time.Time
+	}{
+		{"Nightly, heavy batch", time.Date(2026, time.March, 1, 14, 0, 0, 0, time.UTC)},
+		{"Nightly, heavy batch", time.Date(2026, time.March, 2, 2, 0, 0, 0, time.UTC)},
+		{"Nightly, heavy batch", time.Date(2026, time.March, 2, 14, 0, 0, 0, time.UTC)},
+		// Only one run left before the limit.
+		{"Entry 2", time.Date(2026, time.March, 1, 13, 30, 0, 0, time.UTC)},
+	}
+	if len(cal.Events) != len(expecteds) {
+		t.Fatalf("expected %d events, found %d", len(expecteds), len(cal.Events))
+	}
+	for i, expected := range expecteds {
+		e := cal.Events[i]
+		if e.Summary != expected.summary || !e.Start.Equal(expected.start) {
+			t.Errorf("event %d: (expected) %+v != %q %v (actual)", i, expected, e.Summary, e.Start)
+		}
+	}
+}
+
+func TestWriteICalendarFolding(t *testing.T) {
+	name := strings.Repeat("Ünïcödé ", 30)
+	entries := []*Entry{{ID: 1, Name: name, Schedule: mustParse(t, "@daily")}}
+	var buf bytes.Buffer
+	if err := WriteICalendar(&buf, entries, time.Now(), 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.SplitAfter(buf.String(), "\r\n") {
+		if len(line) > 75+2 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+	cal, err := ReadICalendar(&buf, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 1 || cal.Events[0].Summary != name {
+		t.Errorf("expected summary %q, got %+v", name, cal.Events)
+	}
+}
+
+func TestICalendarHandler(t *testing.T) {
+	cron := New()
+	cron.AddFunc("0 0 * * * *", func() {}, WithName("Hourly"))
+	cron.Start()
+	defer cron.Stop()
+
+	rec := httptest.NewRecorder()
+	cron.ICalendarHandler(5).ServeHTTP(rec, httptest.NewRequest("GET", "/cron.ics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	cal, err := ReadICalendar(rec.Body, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 5 || cal.Events[0].Summary != "Hourly" {
+		t.Errorf("expected 5 hourly events, got %+v", cal.Events)
+	}
+}
+
+func mustParse(t *testing.T, spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// String returns the shortest canonical spec of the schedule, which Parse
+// gives back: descriptors such as "@daily" where they match, or else the six
+// fields with numbers, ranges and steps, a "CRON_TZ=" prefix for the location,
+// and a seventh field for the years if there are any (which needs a parser with
+// the Year option).
+func (s *SpecSchedule) String() string {
+	var prefix string
+	if s.Location != nil {
+		prefix = "CRON_TZ=" + s.Location.String() + " "
+	}
+	for _, descriptor := range []string{"@yearly", "@monthly", "@weekly", "@daily", "@hourly"} {
+		d, _ := parseDescriptor(descriptor, nil)
+		if s.sameBits(d.(*SpecSchedule)) {
+			return prefix + descriptor
+		}
+	}
+
+	fields := []string{
+		formatField(s.Second, seconds, nil),
+		formatField(s.Minute, minutes, nil),
+		formatField(s.Hour, hours, nil),
+		formatField(s.Dom, dom, s.domExtensions()),
+		formatField(s.Month, months, nil),
+		formatField(s.Dow, dow, s.dowExtensions()),
+	}
+	if year := yearValues(s.Year); len(year) > 0 {
+		fields = append(fields, strings.Join(formatValues(year, years), ","))
+	}
+	return prefix + strings.Join(fields, " ")
+}
+
+// sameBits reports whether the schedules activate at the same times, given in
+// the same time zone.
+func (s *SpecSchedule) sameBits(o *SpecSchedule) bool {
+	return s.Second == o.Second && s.Minute == o.Minute && s.Hour == o.Hour &&
+		s.Dom == o.Dom && s.Month == o.Month && s.Dow == o.Dow &&
+		s.DomLast == o.DomLast && s.DomWeekday == o.DomWeekday &&
+		s.DowLast == o.DowLast && s.DowNth == o.DowNth && s.Year == o.Year
+}
+
+// domExtensions returns the last days and nearest weekdays of the schedule, as
+// in the day of month field.
+func (s *SpecSchedule) domExtensions() []string {
+	var exprs []string
+	for n := uint(0); n < dom.max; n++ {
+		switch {
+		case s.DomLast&(1<<n) == 0:
+		case n == 0:
+			exprs = append(exprs, "L")
+		default:
+			exprs = append(exprs, "L-"+strconv.Itoa(int(n)))
+		}
+	}
+	for n := uint(0); n <= dom.max; n++ {
+		switch {
+		case s.DomWeekday&(1<<n) == 0:
+		case n == 0:
+			exprs = append(exprs, "LW")
+		default:
+			exprs = append(exprs, strconv.Itoa(int(n))+"W")
+		}
+	}
+	return exprs
+}
+
+// dowExtensions returns the last and nth days of week of the schedule, as in
+// the day of week field.
+func (s *SpecSchedule) dowExtensions() []string {
+	var exprs []string
+	for w := uint(0); w <= dow.max; w++ {
+		if s.DowLast&(1<<w) > 0 {
+			exprs = append(exprs, strconv.Itoa(int(w))+"L")
+		}
+	}
+	for n := uint(0); n < 5; n++ {
+		for w := uint(0); w <= dow.max; w++ {
+			if s.DowNth&(1<<(7*n+w)) > 0 {
+				exprs = append(exprs, fmt.Sprintf("%d#%d", w, n+1))
+			}
+		}
+	}
+	return exprs
+}
+
+// formatField returns the expression of a field: "*", or "*/step" and the
+// values it does not cover if the star bit is set, or else the values as
+// numbers, ranges and steps, followed by the given extra expressions.
+func formatField(bits uint64, r bounds, extra []string) string {
+	v := values(bits, r)
+	var exprs []string
+	if bits&starBit > 0 {
+		if len(v) == int(r.max-r.min+1) {
+			return strings.Join(append([]string{"*"}, extra...), ",")
+		}
+		// Find the smallest step whose values are all set. A step beyond the
+		// end of the field stands for the first value alone.
+		for step := uint(2); step <= r.max-r.min+1; step++ {
+			var stepped uint64
+			for i := r.min; i <= r.max; i += step {
+				stepped |= 1 << i
+			}
+			if bits&stepped == stepped {
+				exprs = append(exprs, "*/"+strconv.Itoa(int(step)))
+				v = values(bits&^stepped, r)
+				break
+			}
+		}
+	}
+	exprs = append(exprs, formatValues(v, r)...)
+	return strings.Join(append(exprs, extra...), ",")
+}
+
+// formatValues returns the values as a single stepped range if they are evenly
+// spaced, or else as numbers and ranges of consecutive numbers.
+func formatValues(v []uint, r bounds) []string {
+	if step := progression(v); step > 1 {
+		first, last := v[0], v[len(v)-1]
+		if last+step > r.max {
+			// "N/step" means "N-max/step".
+			return []string{fmt.Sprintf("%d/%d", first, step)}
+		}
+		return []string{fmt.Sprintf("%d-%d/%d", first, last, step)}
+	}
+	var exprs []string
+	for i := 0; i < len(v); {
+		j := i
+		for j+1 < len(v) && v[j+1] == v[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			exprs = append(exprs, fmt.Sprintf("%d-%d", v[i], v[j]))
+		case j-i == 1:
+			exprs = append(exprs, strconv.Itoa(int(v[i])), strconv.Itoa(int(v[j])))
+		default:
+			exprs = append(exprs, strconv.Itoa(int(v[i])))
+		}
+		i = j + 1
+	}
+	return exprs
+}
@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpecString(t *testing.T) {
+	yearParser := NewParser(Second | Minute | Hour | Dom | Month | DowOptional | Year | Descriptor)
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"* * * * * *", "* * * * * *"},
+		{"0 30 9 * * mon-fri", "0 30 9 * * 1-5"},
+		{"0 0 0 * * ?", "@daily"},
+		{"@midnight", "@daily"},
+		{"@annually", "@yearly"},
+		{"0 0 * * * *", "@hourly"},
+		{"TZ=Europe/Paris @weekly", "CRON_TZ=Europe/Paris @weekly"},
+		{"CRON_TZ=UTC 0 30 9 * * *", "CRON_TZ=UTC 0 30 9 * * *"},
+		{"0 0,15,30,45 * * * *", "0 0/15 * * * *"},
+		{"0 */15 * * * *", "0 */15 * * * *"},
+		{"0 */15,7 * * * *", "0 */15,7 * * * *"},
+		{"0 0 */30 * * *", "0 0 */24 * * *"},
+		{"0 5-20/5 * * * *", "0 5-20/5 * * * *"},
+		{"0 1,2,3,4,10,11 * * * *", "0 1-4,10,11 * * * *"},
+		{"0 0 12 1 jan,feb,mar ?", "0 0 12 1 1-3 *"},
+		{"0 0 18 L,L-3,15W,LW * ?", "0 0 18 L,L-3,LW,15W * *"},
+		{"0 0 18 1,L * ?", "0 0 18 1,L * *"},
+		{"0 0 9 ? * MON#2,FRIL", "0 0 9 * * 5L,1#2"},
+		{"0 0 12 1 1 ? 2027-2029", "0 0 12 1 1 * 2027-2029"},
+		{"0 0 12 1 1 ? 2028/4", "0 0 12 1 1 * 2028/4"},
+	}
+	for _, c := range tests {
+		schedule, err := yearParser.Parse(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		actual := schedule.(*SpecSchedule).String()
+		if actual != c.expected {
+			t.Errorf("%s: (expected) %q != %q (actual)", c.spec, c.expected, actual)
+		}
+
+		// The spec gives back an equal schedule.
+		again, err := yearParser.Parse(actual)
+		if err != nil {
+			t.Errorf("%s: %v", actual, err)
+			continue
+		}
+		if !reflect.DeepEqual(schedule, again) {
+			t.Errorf("%s: round trip through %q gives %+v, not %+v", c.spec, actual, again, schedule)
+		}
+	}
+}
+
+func TestScheduleString(t *testing.T) {
+	tests := []struct {
+		schedule interface {
+			Schedule
+			String() string
+		}
+		expected string
+	}{
+		{Every(90 * time.Minute), "@every 1h30m0s"},
+		{At(time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC)), "@at 2026-12-01T09:00:00Z"},
+	}
+	for _, c := range tests {
+		actual := c.schedule.String()
+		if actual != c.expected {
+			t.Errorf("(expected) %q != %q (actual)", c.expected, actual)
+		}
+		if again, err := Parse(actual); err != nil || again != c.schedule {
+			t.Errorf("%s: round trip gives %v, %v", actual, again, err)
+		}
+	}
+}
@@ -0,0 +1,737 @@
+package cron
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICalendar is a set of events read from an iCalendar (.ics) file, such as a
+// list of public holidays. It may be used as a Calendar that excludes the times
+// during its events, or as a Schedule that activates at the start of each event.
+type ICalendar struct {
+	Events []*ICalEvent
+}
+
+// ICalEvent is a VEVENT of an iCalendar file, possibly recurring.
+type ICalEvent struct {
+	// The SUMMARY of the event.
+	Summary string
+
+	// The start of the first occurrence of the event.
+	Start time.Time
+
+	// Whether the event lasts whole days, rather than starting at a time of day.
+	AllDay bool
+
+	// The length of each occurrence, as a number of days plus a duration.
+	days     int
+	duration time.Duration
+
+	// The recurrence rule, if the event recurs, and the starts of the
+	// occurrences excluded from it.
+	rule    *recurrence
+	exdates []time.Time
+}
+
+// recurrence is a parsed RRULE.
+type recurrence struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []time.Month
+}
+
+// weekdayNum is an entry of BYDAY, e.g. "MO" (every Monday) or "-1FR" (the last
+// Friday). The ordinal is 0 if it is not given.
+type weekdayNum struct {
+	ordinal int
+	weekday time.Weekday
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// cycleUnits is the number of units of each FREQ in a 400 year cycle of the
+// calendar. Since the days of the calendar repeat in each cycle, a recurrence
+// with no occurrence during that many consecutive periods has no more.
+var cycleUnits = map[string]int{
+	"DAILY":   146097,
+	"WEEKLY":  20871,
+	"MONTHLY": 4800,
+	"YEARLY":  400,
+}
+
+// LoadICalendar reads an ICalendar from the named file. See ReadICalendar.
+func LoadICalendar(name string, loc *time.Location) (*ICalendar, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadICalendar(f, loc)
+}
+
+// ReadICalendar reads the VEVENTs of an iCalendar (RFC 5545) document. All-day
+// events and times without a time zone are interpreted in loc, or in the local
+// time zone if loc is nil.
+//
+// Events may recur, as given by an RRULE with a FREQ of DAILY, WEEKLY, MONTHLY
+// or YEARLY, along with INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH
+// parts, and EXDATEs. Other rule parts are rejected.
+func ReadICalendar(r io.Reader, loc *time.Location) (*ICalendar, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cal   = &ICalendar{}
+		event *icalProperties
+		depth int
+	)
+	for _, line := range lines {
+		if line.text == "" {
+			continue
+		}
+		name, params, value, err := parseContentLine(line.text)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse iCalendar line %d: %s", line.number, err)
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && event == nil:
+			event = &icalProperties{line: line.number}
+		case name == "BEGIN" && event != nil:
+			depth++
+		case name == "END" && event != nil && depth > 0:
+			depth--
+		case name == "END" && event != nil && strings.EqualFold(value, "VEVENT"):
+			e, err := event.event(loc)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse iCalendar event at line %d: %s", event.line, err)
+			}
+			cal.Events = append(cal.Events, e)
+			event = nil
+		case event != nil && depth == 0:
+			event.props = append(event.props, icalProperty{name, params, value})
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("Unterminated VEVENT at line %d", event.line)
+	}
+	return cal, nil
+}
+
+// icalLine is an unfolded content line, with the number of its first line.
+type icalLine struct {
+	number int
+	text   string
+}
+
+// unfoldLines reads the content lines of an iCalendar document, joining the
+// lines that continue the previous one (those starting with a space or tab).
+func unfoldLines(r io.Reader) ([]icalLine, error) {
+	var lines []icalLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) > 0 && (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		lines = append(lines, icalLine{n, text})
+	}
+	return lines, scanner.Err()
+}
+
+// parseContentLine splits a content line into its upper-cased name, its
+// parameters, and its value.
+func parseContentLine(line string) (string, map[string]string, string, error) {
+	colon, quoted := -1, false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon == -1 {
+		return "", nil, "", fmt.Errorf("Missing colon: %s", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return "", nil, "", fmt.Errorf("Malformed parameter: %s", param)
+		}
+		params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], nil
+}
+
+// icalProperties are the properties of a VEVENT, as they are read.
+type icalProperties struct {
+	line  int
+	props []icalProperty
+}
+
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// event builds an ICalEvent from the properties of a VEVENT.
+func (p *icalProperties) event(loc *time.Location) (*ICalEvent, error) {
+	var (
+		e                = &ICalEvent{}
+		end              time.Time
+		hasStart, hasEnd bool
+		hasDuration      bool
+		rrule            string
+		exdates          []icalProperty
+		err              error
+	)
+	for _, prop := range p.props {
+		switch prop.name {
+		case "SUMMARY":
+			e.Summary = unescapeText(prop.value)
+		case "DTSTART":
+			if e.Start, e.AllDay, err = parseICalTime(prop.value, prop.params, loc); err != nil {
+				return nil, err
+			}
+			hasStart = true
+		case "DTEND":
+			if end, _, err = parseICalTime(prop.value, prop.params, loc); err != nil {
+				return nil, err
+			}
+			hasEnd = true
+		case "DURATION":
+			if e.days, e.duration, err = parseICalDuration(prop.value); err != nil {
+				return nil, err
+			}
+			hasDuration = true
+		case "RRULE":
+			rrule = prop.value
+		case "EXDATE":
+			exdates = append(exdates, prop)
+		}
+	}
+	if !hasStart {
+		return nil, fmt.Errorf("Missing DTSTART")
+	}
+
+	switch {
+	case hasEnd && e.AllDay:
+		e.days = daysBetween(e.Start, end)
+	case hasEnd:
+		e.duration = end.Sub(e.Start)
+	case !hasDuration && e.AllDay:
+		e.days = 1
+	}
+	if e.days < 0 || e.duration < 0 {
+		return nil, fmt.Errorf("Event ends before it starts")
+	}
+
+	if rrule != "" {
+		if e.rule, err = parseRecurrence(rrule, e.Start.Location()); err != nil {
+			return nil, err
+		}
+	}
+	for _, prop := range exdates {
+		for _, value := range strings.Split(prop.value, ",") {
+			t, _, err := parseICalTime(value, prop.params, loc)
+			if err != nil {
+				return nil, err
+			}
+			e.exdates = append(e.exdates, t)
+		}
+	}
+
+	// Replace a COUNT by the last occurrence it allows, so that searches may
+	// start from any period rather than count occurrences from the first. A
+	// rule without any occurrence ends before it starts, so that searches do
+	// not go through a whole cycle of empty periods each time.
+	if e.rule != nil {
+		last := e.Start.Add(-time.Nanosecond)
+		e.each(e.Start, func(start time.Time) bool {
+			last = start
+			return e.rule.count > 0
+		})
+		if e.rule.count > 0 || last.Before(e.Start) {
+			e.rule.until, e.rule.count = last, 0
+		}
+	}
+	return e, nil
+}
+
+// parseICalTime parses a DATE or DATE-TIME value. Times in UTC end in "Z", and
+// others are in the time zone given by the TZID parameter, or in loc.
+func parseICalTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	if tzid := params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(strings.TrimPrefix(tzid, "/")); err != nil {
+			return time.Time{}, false, fmt.Errorf("Provided bad location %s: %s", tzid, err)
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+var icalDuration = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICalDuration parses a DURATION value into a number of days and a duration.
+func parseICalDuration(value string) (int, time.Duration, error) {
+	m := icalDuration.FindStringSubmatch(value)
+	if m == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, 0, fmt.Errorf("Failed to parse duration: %s", value)
+	}
+	n := make([]int, len(m))
+	for i := 2; i < len(m); i++ {
+		if m[i] != "" {
+			n[i], _ = strconv.Atoi(m[i])
+		}
+	}
+	days := 7*n[2] + n[3]
+	d := time.Duration(n[4])*time.Hour + time.Duration(n[5])*time.Minute + time.Duration(n[6])*time.Second
+	if m[1] == "-" {
+		return -days, -d, nil
+	}
+	return days, d, nil
+}
+
+// parseRecurrence parses an RRULE value, for an event that starts in loc.
+func parseRecurrence(value string, loc *time.Location) (*recurrence, error) {
+	r := &recurrence{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Malformed RRULE part: %s", part)
+		}
+		var err error
+		switch name, v := strings.ToUpper(kv[0]), kv[1]; name {
+		case "FREQ":
+			r.freq = strings.ToUpper(v)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(v)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("INTERVAL should be a positive number: %s", v)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(v)
+			if err == nil && r.count < 1 {
+				err = fmt.Errorf("COUNT should be a positive number: %s", v)
+			}
+		case "UNTIL":
+			var date bool
+			r.until, date, err = parseICalTime(v, nil, loc)
+			if date {
+				// The last day is included.
+				r.until = r.until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(v, ",") {
+				var wd weekdayNum
+				if wd, err = parseWeekdayNum(day); err != nil {
+					break
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(v, ",") {
+				var d int
+				if d, err = strconv.Atoi(day); err == nil && (d == 0 || d < -31 || d > 31) {
+					err = fmt.Errorf("BYMONTHDAY out of range: %s", day)
+				}
+				if err != nil {
+					break
+				}
+				r.byMonthDay = append(r.byMonthDay, d)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(v, ",") {
+				var m int
+				if m, err = strconv.Atoi(month); err == nil && (m < 1 || m > 12) {
+					err = fmt.Errorf("BYMONTH out of range: %s", month)
+				}
+				if err != nil {
+					break
+				}
+				r.byMonth = append(r.byMonth, time.Month(m))
+			}
+		case "WKST":
+			// Weeks start on Monday, which is the default.
+			if strings.ToUpper(v) != "MO" {
+				err = fmt.Errorf("Unsupported RRULE part: %s", part)
+			}
+		default:
+			err = fmt.Errorf("Unsupported RRULE part: %s", part)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "MONTHLY":
+	case "WEEKLY":
+		if len(r.byMonthDay) > 0 {
+			return nil, fmt.Errorf("BYMONTHDAY is not allowed with FREQ=WEEKLY")
+		}
+	case "YEARLY":
+		if len(r.byMonth) == 0 && (len(r.byDay) > 0 || len(r.byMonthDay) > 0) {
+			return nil, fmt.Errorf("Unsupported RRULE: BYDAY or BYMONTHDAY without BYMONTH in FREQ=YEARLY")
+		}
+	case "":
+		return nil, fmt.Errorf("Missing FREQ in RRULE: %s", value)
+	default:
+		return nil, fmt.Errorf("Unsupported FREQ in RRULE: %s", r.freq)
+	}
+	for _, wd := range r.byDay {
+		if wd.ordinal != 0 && (r.freq == "DAILY" || r.freq == "WEEKLY") {
+			return nil, fmt.Errorf("Ordinal BYDAY is not allowed with FREQ=%s", r.freq)
+		}
+	}
+	return r, nil
+}
+
+// parseWeekdayNum parses an entry of BYDAY, such as "MO", "2TU" or "-1FR".
+func parseWeekdayNum(value string) (weekdayNum, error) {
+	value = strings.ToUpper(value)
+	if len(value) < 2 {
+		return weekdayNum{}, fmt.Errorf("Malformed BYDAY: %s", value)
+	}
+	weekday, ok := icalWeekdays[value[len(value)-2:]]
+	if !ok {
+		return weekdayNum{}, fmt.Errorf("Malformed BYDAY: %s", value)
+	}
+	var ordinal int
+	if prefix := value[:len(value)-2]; prefix != "" {
+		var err error
+		if ordinal, err = strconv.Atoi(prefix); err != nil || ordinal == 0 || ordinal < -5 || ordinal > 5 {
+			return weekdayNum{}, fmt.Errorf("Malformed BYDAY: %s", value)
+		}
+	}
+	return weekdayNum{ordinal, weekday}, nil
+}
+
+// unescapeText unescapes a TEXT value.
+func unescapeText(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// daysBetween returns the number of calendar days from the date of a to the
+// date of b.
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua) / (24 * time.Hour))
+}
+
+// End returns the end of the occurrence of the event that starts at start.
+func (e *ICalEvent) End(start time.Time) time.Time {
+	return start.AddDate(0, 0, e.days).Add(e.duration)
+}
+
+// Next returns the start of the next occurrence of the event, later than the
+// given time, or the zero time if there is none.
+func (e *ICalEvent) Next(t time.Time) time.Time {
+	var next time.Time
+	e.each(t, func(start time.Time) bool {
+		if start.After(t) {
+			next = start.In(t.Location())
+			return false
+		}
+		return true
+	})
+	return next
+}
+
+// each calls f with the start of each occurrence of the event, in order, until
+// f returns false or there are no more occurrences. Occurrences of recurrence
+// periods before the one containing from are skipped, which is only possible
+// without a COUNT.
+func (e *ICalEvent) each(from time.Time, f func(time.Time) bool) {
+	if e.rule == nil {
+		if !e.excluded(e.Start) {
+			f(e.Start)
+		}
+		return
+	}
+
+	if !e.rule.until.IsZero() && e.rule.until.Before(e.Start) {
+		return
+	}
+	first := 0
+	if e.rule.count == 0 {
+		first = e.rule.period(e.Start, from)
+	}
+	count, empty := 0, 0
+	for period := first; empty < cycleUnits[e.rule.freq]; period++ {
+		starts := e.rule.expand(e.Start, period)
+		if len(starts) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, start := range starts {
+			if start.Before(e.Start) {
+				continue
+			}
+			if !e.rule.until.IsZero() && start.After(e.rule.until) {
+				return
+			}
+			if count++; e.rule.count > 0 && count > e.rule.count {
+				return
+			}
+			if e.excluded(start) {
+				continue
+			}
+			if !f(start) {
+				return
+			}
+		}
+	}
+}
+
+// excluded returns true if the occurrence starting at start is an EXDATE.
+func (e *ICalEvent) excluded(start time.Time) bool {
+	for _, t := range e.exdates {
+		if t.Equal(start) || e.AllDay && daysBetween(t, start) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// period returns the index of the period of the recurrence that contains t,
+// counting from the one containing dtstart, or 0 if t is before it.
+func (r *recurrence) period(dtstart, t time.Time) int {
+	t = t.In(dtstart.Location())
+	var units int
+	switch r.freq {
+	case "DAILY":
+		units = daysBetween(dtstart, t)
+	case "WEEKLY":
+		monday := dtstart.AddDate(0, 0, -(int(dtstart.Weekday())+6)%7)
+		units = daysBetween(monday, t) / 7
+	case "MONTHLY":
+		units = 12*(t.Year()-dtstart.Year()) + int(t.Month()) - int(dtstart.Month())
+	case "YEARLY":
+		units = t.Year() - dtstart.Year()
+	}
+	if units < 0 {
+		return 0
+	}
+	return units / r.interval
+}
+
+// expand returns the starts of the occurrences within the given period of the
+// recurrence, counting periods from the one containing dtstart, in order.
+func (r *recurrence) expand(dtstart time.Time, period int) []time.Time {
+	var days []time.Time
+	switch n := period * r.interval; r.freq {
+	case "DAILY":
+		d := dtstart.AddDate(0, 0, n)
+		if r.matchesMonth(d.Month()) && r.matchesMonthDay(d) && r.matchesWeekday(d.Weekday()) {
+			days = append(days, d)
+		}
+
+	case "WEEKLY":
+		monday := dtstart.AddDate(0, 0, 7*n-(int(dtstart.Weekday())+6)%7)
+		for i := 0; i < 7; i++ {
+			d := monday.AddDate(0, 0, i)
+			if len(r.byDay) == 0 && d.Weekday() != dtstart.Weekday() ||
+				len(r.byDay) > 0 && !r.matchesWeekday(d.Weekday()) {
+				continue
+			}
+			if r.matchesMonth(d.Month()) {
+				days = append(days, d)
+			}
+		}
+
+	case "MONTHLY":
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchesMonth(first.Month()) {
+			days = r.monthDays(first.Year(), first.Month(), dtstart)
+		}
+
+	case "YEARLY":
+		months := r.byMonth
+		if len(months) == 0 {
+			months = []time.Month{dtstart.Month()}
+		}
+		for _, m := range months {
+			days = append(days, r.monthDays(dtstart.Year()+n, m, dtstart)...)
+		}
+	}
+
+	starts := make([]time.Time, 0, len(days))
+	for _, d := range days {
+		starts = append(starts, time.Date(d.Year(), d.Month(), d.Day(),
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location()))
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	return starts
+}
+
+// monthDays returns the days of the given month selected by BYMONTHDAY and
+// BYDAY, or the day of the month of dtstart if there are neither.
+func (r *recurrence) monthDays(year int, month time.Month, dtstart time.Time) []time.Time {
+	n := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	var days []time.Time
+	for day := 1; day <= n; day++ {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		switch {
+		case len(r.byMonthDay) == 0 && len(r.byDay) == 0:
+			if day != dtstart.Day() {
+				continue
+			}
+		case len(r.byDay) == 0:
+			if !r.matchesMonthDay(d) {
+				continue
+			}
+		case !r.matchesMonthDay(d) || !r.matchesWeekdayNum(d, n):
+			continue
+		}
+		days = append(days, d)
+	}
+	return days
+}
+
+func (r *recurrence) matchesMonth(m time.Month) bool {
+	if len(r.byMonth) == 0 {
+		return true
+	}
+	for _, month := range r.byMonth {
+		if month == m {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay returns true if BYMONTHDAY is empty or selects the day of d,
+// counting negative days from the end of the month.
+func (r *recurrence) matchesMonthDay(d time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+	n := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, day := range r.byMonthDay {
+		if day == d.Day() || day < 0 && n+day+1 == d.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday returns true if BYDAY is empty or includes the weekday.
+func (r *recurrence) matchesWeekday(w time.Weekday) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, wd := range r.byDay {
+		if wd.weekday == w {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekdayNum returns true if BYDAY selects d within its month of n days,
+// such as the second Tuesday or the last Friday.
+func (r *recurrence) matchesWeekdayNum(d time.Time, n int) bool {
+	for _, wd := range r.byDay {
+		if wd.weekday != d.Weekday() {
+			continue
+		}
+		if wd.ordinal == 0 ||
+			wd.ordinal > 0 && (d.Day()-1)/7+1 == wd.ordinal ||
+			wd.ordinal < 0 && (n-d.Day())/7+1 == -wd.ordinal {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the start of the next event occurrence later than the given time,
+// or the zero time if there is none.
+func (c *ICalendar) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, e := range c.Events {
+		if n := e.Next(t); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// Excludes reports whether t falls during an event occurrence. Adjacent and
+// overlapping occurrences are merged, so the returned end of the exclusion is
+// never itself excluded.
+func (c *ICalendar) Excludes(t time.Time) (bool, time.Time) {
+	until := t
+	for {
+		end := c.exclusionEnd(until)
+		if !end.After(until) {
+			break
+		}
+		until = end
+	}
+	return until.After(t), until.In(t.Location())
+}
+
+// exclusionEnd returns the end of the latest-ending event occurrence during
+// which t falls, or t if there is none.
+func (c *ICalendar) exclusionEnd(t time.Time) time.Time {
+	end := t
+	for _, e := range c.Events {
+		// Occurrences that start a day before the length of an occurrence
+		// (which may be longer across a daylight saving time transition) end
+		// before t.
+		from := t.AddDate(0, 0, -e.days-1).Add(-e.duration)
+		e.each(from, func(start time.Time) bool {
+			if start.After(t) {
+				return false
+			}
+			if occurrenceEnd := e.End(start); occurrenceEnd.After(end) {
+				end = occurrenceEnd
+			}
+			return true
+		})
+	}
+	return end
+}
@@ -0,0 +1,235 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadICalendar(t *testing.T, name string) *ICalendar {
+	cal, err := LoadICalendar(name, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cal
+}
+
+func TestLoadICalendar(t *testing.T) {
+	cal := loadICalendar(t, "testdata/holidays.ics")
+	expecteds := []struct {
+		summary string
+		start   time.Time
+		allDay  bool
+	}{
+		{"Christmas and Boxing Day", time.Date(2025, time.December, 25, 0, 0, 0, 0, time.UTC), true},
+		{"New Year's Day", time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), true},
+		{"Memorial Day", time.Date(2025, time.May, 26, 0, 0, 0, 0, time.UTC), true},
+		{"Thanksgiving", time.Date(2025, time.November, 27, 0, 0, 0, 0, time.UTC), true},
+		{"Summer shutdown, all offices", time.Date(2026, time.August, 3, 0, 0, 0, 0, time.UTC), true},
+	}
+	if len(cal.Events) != len(expecteds) {
+		t.Fatalf("expected %d events, found %d", len(expecteds), len(cal.Events))
+	}
+	for i, expected := range expecteds {
+		e := cal.Events[i]
+		if e.Summary != expected.summary || !e.Start.Equal(expected.start) || e.AllDay != expected.allDay {
+			t.Errorf("event %d: (expected) %+v != %q %v %v (actual)", i, expected, e.Summary, e.Start, e.AllDay)
+		}
+	}
+
+	cal = loadICalendar(t, "testdata/maintenance.ics")
+	if len(cal.Events) != 2 || cal.Events[0].Summary != "Database maintenance" || cal.Events[0].AllDay {
+		t.Errorf("unexpected events %+v", cal.Events)
+	}
+	if start := cal.Events[0].Start; start.Location().String() != "America/New_York" || start.Hour() != 3 {
+		t.Errorf("expected start at 3am in New York, got %v", start)
+	}
+}
+
+func TestICalendarExcludes(t *testing.T) {
+	cal := loadICalendar(t, "testdata/holidays.ics")
+	tests := []struct {
+		time     string
+		excluded bool
+		until    string
+	}{
+		// Yearly, two days
+		{"2025-12-24T23:59:59Z", false, ""},
+		{"2025-12-25T09:00:00Z", true, "2025-12-27T00:00:00Z"},
+		{"2026-12-26T23:59:59Z", true, "2026-12-27T00:00:00Z"},
+		{"2030-12-25T00:00:00Z", true, "2030-12-27T00:00:00Z"},
+		{"2026-12-27T00:00:00Z", false, ""},
+
+		// Single day, adjacent to nothing
+		{"2026-01-01T12:00:00Z", true, "2026-01-02T00:00:00Z"},
+		{"2027-01-01T12:00:00Z", false, ""},
+
+		// Last Monday of May
+		{"2026-05-25T12:00:00Z", true, "2026-05-26T00:00:00Z"},
+		{"2026-05-18T12:00:00Z", false, ""},
+		{"2027-05-31T12:00:00Z", true, "2027-06-01T00:00:00Z"},
+
+		// Fourth Thursday of November, and the day after
+		{"2026-11-26T12:00:00Z", true, "2026-11-28T00:00:00Z"},
+		{"2026-11-27T12:00:00Z", true, "2026-11-28T00:00:00Z"},
+		{"2026-11-19T12:00:00Z", false, ""},
+
+		// Dates in between, exclusive of the end
+		{"2026-08-14T23:00:00Z", true, "2026-08-15T00:00:00Z"},
+		{"2026-08-15T00:00:00Z", false, ""},
+
+		// Before the first occurrence
+		{"2024-12-25T12:00:00Z", false, ""},
+	}
+
+	for _, c := range tests {
+		excluded, until := cal.Excludes(getTimeRFC3339(c.time))
+		if excluded != c.excluded || excluded && !until.Equal(getTimeRFC3339(c.until)) {
+			t.Errorf("%s: (expected) %v until %s != %v until %v (actual)", c.time, c.excluded, c.until, excluded, until)
+		}
+	}
+}
+
+func TestICalendarNext(t *testing.T) {
+	cal := loadICalendar(t, "testdata/maintenance.ics")
+	tests := []struct {
+		time, expected string
+	}{
+		// Weekly on Sundays at 3am in New York, except January 18th
+		{"2026-01-01T00:00:00Z", "2026-01-04T08:00:00Z"},
+		{"2026-01-04T08:00:00Z", "2026-01-11T08:00:00Z"},
+		{"2026-01-11T08:00:00Z", "2026-01-15T17:00:00Z"},
+		{"2026-01-15T17:00:00Z", "2026-01-25T08:00:00Z"},
+
+		// Monthly on the 15th and the last day, until April 30th
+		{"2026-01-25T08:00:00Z", "2026-01-31T17:00:00Z"},
+		{"2026-02-22T08:00:00Z", "2026-02-28T17:00:00Z"},
+
+		// Daylight saving time starts on March 8th, the last of ten Sundays
+		{"2026-03-01T08:00:00Z", "2026-03-08T07:00:00Z"},
+		{"2026-03-08T07:00:00Z", "2026-03-15T17:00:00Z"},
+		{"2026-04-15T17:00:00Z", "2026-04-30T17:00:00Z"},
+		{"2026-04-30T17:00:00Z", ""},
+	}
+
+	for _, c := range tests {
+		actual := cal.Next(getTimeRFC3339(c.time))
+		expected := getTimeRFC3339(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.time, expected, actual)
+		}
+	}
+}
+
+// Test excluding the holidays of an iCalendar from a schedule.
+func TestICalendarExcludeSchedule(t *testing.T) {
+	cal := loadICalendar(t, "testdata/holidays.ics")
+	weekdays, _ := Parse("0 0 9 * * MON-FRI")
+	tests := []struct {
+		time, expected string
+	}{
+		{"2025-12-24T12:00:00Z", "2025-12-29T09:00:00Z"},
+		{"2025-12-31T12:00:00Z", "2026-01-02T09:00:00Z"},
+		{"2026-05-22T12:00:00Z", "2026-05-26T09:00:00Z"},
+		{"2026-07-31T12:00:00Z", "2026-08-17T09:00:00Z"},
+	}
+
+	for _, c := range tests {
+		actual := Exclude(weekdays, cal).Next(getTimeRFC3339(c.time))
+		expected := getTimeRFC3339(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.time, expected, actual)
+		}
+	}
+}
+
+// Test recurrences that are searched far from their start, or that have long
+// runs of periods without an occurrence.
+func TestICalendarRecurrence(t *testing.T) {
+	event := func(props ...string) *ICalendar {
+		input := "BEGIN:VCALENDAR\nBEGIN:VEVENT\n" + strings.Join(props, "\n") + "\nEND:VEVENT\nEND:VCALENDAR\n"
+		cal, err := ReadICalendar(strings.NewReader(input), time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cal
+	}
+	leapDays := event("DTSTART;VALUE=DATE:20000229", "RRULE:FREQ=DAILY;BYMONTH=2;BYMONTHDAY=29")
+	daily := event("DTSTART:19000101T090000Z", "DURATION:PT1H", "RRULE:FREQ=DAILY;INTERVAL=3")
+	counted := event("DTSTART;VALUE=DATE:20260105", "RRULE:FREQ=WEEKLY;COUNT=3", "EXDATE;VALUE=DATE:20260119")
+	never := event("DTSTART;VALUE=DATE:20260101", "RRULE:FREQ=DAILY;BYMONTH=2;BYMONTHDAY=30")
+	tests := []struct {
+		cal      *ICalendar
+		time     string
+		expected string
+	}{
+		// No leap day in 2100, eight years after the previous one
+		{leapDays, "2000-03-01T00:00:00Z", "2004-02-29T00:00:00Z"},
+		{leapDays, "2096-03-01T00:00:00Z", "2104-02-29T00:00:00Z"},
+
+		// Every three days since 1900
+		{daily, "2026-10-19T12:00:00Z", "2026-10-21T09:00:00Z"},
+		{daily, "2026-10-21T09:00:00Z", "2026-10-24T09:00:00Z"},
+
+		// Three Mondays, one of them excluded
+		{counted, "2026-01-05T00:00:00Z", "2026-01-12T00:00:00Z"},
+		{counted, "2026-01-12T00:00:00Z", ""},
+
+		{never, "2026-01-01T00:00:00Z", ""},
+	}
+	for _, c := range tests {
+		actual := c.cal.Next(getTimeRFC3339(c.time))
+		if expected := getTimeRFC3339(c.expected); !actual.Equal(expected) {
+			t.Errorf("%s: (expected) %v != %v (actual)", c.time, expected, actual)
+		}
+	}
+
+	if excluded, until := daily.Excludes(getTimeRFC3339("2026-10-21T09:30:00Z")); !excluded || !until.Equal(getTimeRFC3339("2026-10-21T10:00:00Z")) {
+		t.Errorf("expected an exclusion until 10:00, got %v until %v", excluded, until)
+	}
+	if excluded, _ := daily.Excludes(getTimeRFC3339("2026-10-22T09:30:00Z")); excluded {
+		t.Errorf("expected no exclusion on a day without an occurrence")
+	}
+}
+
+func TestReadICalendarErrors(t *testing.T) {
+	event := func(props ...string) string {
+		return "BEGIN:VCALENDAR\nBEGIN:VEVENT\n" + strings.Join(props, "\n") + "\nEND:VEVENT\nEND:VCALENDAR\n"
+	}
+	tests := []struct {
+		input, err string
+	}{
+		{event("SUMMARY:No start"), "Missing DTSTART"},
+		{event("DTSTART:2026010"), "Failed to parse iCalendar event"},
+		{event("DTSTART;TZID=Bad/Zone:20260101T090000"), "Provided bad location"},
+		{event("DTSTART:20260101T090000Z", "DTEND:20260101T080000Z"), "ends before it starts"},
+		{event("DTSTART:20260101T090000Z", "DURATION:1H"), "Failed to parse duration"},
+		{event("DTSTART:20260101T090000Z", "RRULE:INTERVAL=2"), "Missing FREQ"},
+		{event("DTSTART:20260101T090000Z", "RRULE:FREQ=HOURLY"), "Unsupported FREQ"},
+		{event("DTSTART:20260101T090000Z", "RRULE:FREQ=DAILY;BYSETPOS=1"), "Unsupported RRULE part"},
+		{event("DTSTART:20260101T090000Z", "RRULE:FREQ=WEEKLY;BYDAY=1MO"), "Ordinal BYDAY"},
+		{event("DTSTART:20260101T090000Z", "RRULE:FREQ=MONTHLY;BYDAY=XX"), "Malformed BYDAY"},
+		{event("DTSTART:20260101T090000Z", "RRULE:FREQ=MONTHLY;BYMONTHDAY=32"), "out of range"},
+		{event("DTSTART:20260101T090000Z", "RRULE:FREQ=DAILY;COUNT=0"), "positive number"},
+		{"BEGIN:VEVENT\nDTSTART:20260101T090000Z\n", "Unterminated VEVENT"},
+		{"BEGIN:VEVENT\nDTSTART\n", "Missing colon"},
+	}
+
+	for _, c := range tests {
+		_, err := ReadICalendar(strings.NewReader(c.input), time.UTC)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%q => expected %v, got %v", c.input, c.err, err)
+		}
+	}
+}
+
+func getTimeRFC3339(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
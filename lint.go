@@ -0,0 +1,217 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintCheck is a machine-readable code for the kind of mistake a lint warning
+// is about.
+type LintCheck string
+
+const (
+	LintEveryTick  LintCheck = "every-tick"  // A field matches every value, below a field that does not
+	LintUnevenStep LintCheck = "uneven-step" // A step does not divide its field, so the cadence is uneven
+	LintDaysOr     LintCheck = "days-or"     // Both day fields are restricted, and either one matches
+)
+
+// LintWarning describes a spec that is valid, but probably does not do what was
+// meant.
+type LintWarning struct {
+	// The kind of mistake.
+	Check LintCheck
+
+	// The name and the position of the field the warning is about, and its byte
+	// offsets within the spec, as in ParseError.
+	Field      string
+	Index      int
+	Start, End int
+
+	// Why the spec is suspicious.
+	Message string
+
+	// A spec that probably does what was meant, or empty if there is no obvious
+	// fix.
+	Suggestion string
+}
+
+// timeUnits are the units of the time of day fields, for messages and
+// durations.
+var timeUnits = []struct{ name, duration string }{
+	{"second", "s"},
+	{"minute", "m"},
+	{"hour", "h"},
+}
+
+// Lint returns warnings about the given spec, which is valid but may not do
+// what was meant, using the default parser. See Parser.Lint.
+func Lint(spec string) ([]LintWarning, error) {
+	return defaultParser.Lint(spec)
+}
+
+// Lint returns warnings about common mistakes in the given spec, which parses
+// but probably does not do what was meant:
+//   - a field that matches every value below one that does not, as in
+//     "* 5 * * *", which runs every minute from 05:00 to 05:59
+//   - a step that does not divide its field, as in "*/7" minutes, which runs at
+//     minutes 0, 7, ..., 56 and then 0 again, 4 minutes later
+//   - both the day of month and the day of week restricted, which runs on the
+//     days that match either of them, not both
+//
+// It returns the parse error if the spec is not valid. Descriptors have no
+// warnings.
+func (p Parser) Lint(spec string) ([]LintWarning, error) {
+	schedule, err := p.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := schedule.(*SpecSchedule)
+	_, offset, _ := extractLocation(spec)
+	if rest := strings.TrimSpace(spec[offset:]); !ok || rest[0] == '@' {
+		return nil, nil
+	}
+
+	given, offsets := splitFields(spec[offset:])
+	fields, index := expandFields(given, p.options)
+	l := &linter{spec: spec, fields: fields, index: index, offsets: offsets, offset: offset}
+
+	l.everyTick(s)
+	for place, r := range []bounds{seconds, minutes, hours, dom, months, dow} {
+		if place != 3 {
+			l.unevenStep(place, r, p.options&Descriptor > 0)
+		}
+	}
+	l.daysOr(s)
+	return l.warnings, nil
+}
+
+// linter collects the warnings about a parsed spec.
+type linter struct {
+	spec     string
+	fields   []string
+	index    []int
+	offsets  []int
+	offset   int
+	warnings []LintWarning
+}
+
+// warn adds a warning about the field in the given place.
+func (l *linter) warn(check LintCheck, place int, suggestion, format string, args ...interface{}) {
+	i := l.index[place]
+	start := l.offset + l.offsets[i]
+	l.warnings = append(l.warnings, LintWarning{
+		Check:      check,
+		Field:      fieldNames[place],
+		Index:      i,
+		Start:      start,
+		End:        start + len(l.fields[place]),
+		Message:    fmt.Sprintf(format, args...),
+		Suggestion: suggestion,
+	})
+}
+
+// replace returns the spec with the fields in the given places replaced.
+func (l *linter) replace(values map[int]string) string {
+	spec, shift := l.spec, 0
+	for place := range l.fields {
+		value, ok := values[place]
+		if !ok {
+			continue
+		}
+		start := l.offset + l.offsets[l.index[place]] + shift
+		spec = spec[:start] + value + spec[start+len(l.fields[place]):]
+		shift += len(value) - len(l.fields[place])
+	}
+	return spec
+}
+
+// everyTick warns about the time of day fields that match every value, below
+// one that does not.
+func (l *linter) everyTick(s *SpecSchedule) {
+	masks := []uint64{s.Second, s.Minute, s.Hour}
+	ranges := []bounds{seconds, minutes, hours}
+	every := func(place int) bool {
+		return masks[place]&^starBit == getBits(ranges[place].min, ranges[place].max, 1)
+	}
+
+	// Find the lowest restricted field with an unrestricted one below it.
+	for above := 1; above < len(masks); above++ {
+		if every(above) || !every(above-1) || l.index[above-1] < 0 {
+			continue
+		}
+		fix := make(map[int]string)
+		for place := above - 1; place >= 0 && every(place) && l.index[place] >= 0; place-- {
+			fix[place] = "0"
+		}
+		below, unit := timeUnits[above-1].name, timeUnits[above].name
+		l.warn(LintEveryTick, above-1, l.replace(fix),
+			"The %s field matches every %s, so the job runs every %s of each %s that matches, not once: use 0 to run at the start of the %s",
+			below, below, below, unit, unit)
+		return
+	}
+}
+
+// unevenStep warns about a field in the given place that is a single stepped
+// range up to the end of the field, whose step does not divide the field.
+func (l *linter) unevenStep(place int, r bounds, descriptors bool) {
+	if l.index[place] < 0 || strings.Contains(l.fields[place], ",") || strings.HasPrefix(l.fields[place], "H") {
+		return
+	}
+	start, end, step, _, err := parseRange(l.fields[place], r)
+	if err != nil || step == 1 || end != r.max {
+		return
+	}
+	last := start + (end-start)/step*step
+	wrap := r.max + 1 - last + start - r.min
+	if wrap == step {
+		return
+	}
+
+	name := fieldNames[place]
+	var suggestion string
+	if place < len(timeUnits) {
+		name = timeUnits[place].name
+		// An interval keeps the cadence, if the fields above match every value.
+		if descriptors && l.fromStart(place) {
+			suggestion = fmt.Sprintf("@every %d%s", step, timeUnits[place].duration)
+		}
+	}
+	l.warn(LintUnevenStep, place, suggestion,
+		"The step %d does not divide the %s field: it matches up to %d, and then %d again, %d later instead of %d",
+		step, name, last, start, wrap, step)
+}
+
+// fromStart reports whether the fields above the time of day field in the given
+// place match every value, so that an interval of its unit may replace it.
+func (l *linter) fromStart(place int) bool {
+	for above := place + 1; above < len(l.fields); above++ {
+		if l.fields[above] != "*" && l.fields[above] != "?" {
+			return false
+		}
+	}
+	return true
+}
+
+// daysOr warns if both the day of month and the day of week are restricted.
+func (l *linter) daysOr(s *SpecSchedule) {
+	if s.Dom&starBit > 0 || s.Dow&starBit > 0 {
+		return
+	}
+
+	// A week of days of month with one day of week usually means the nth day of
+	// week of the month, as in "1-7" and "MON".
+	var suggestion string
+	days := s.Dom &^ starBit
+	if _, err := parseIntOrName(l.fields[5], dow.names); err == nil && s.DomLast == 0 && s.DomWeekday == 0 {
+		for n := uint(1); n <= 4; n++ {
+			if days == getBits(7*n-6, 7*n, 1) {
+				suggestion = l.replace(map[int]string{3: "?", 5: fmt.Sprintf("%s#%d", l.fields[5], n)})
+			}
+		}
+	}
+	if suggestion == "" {
+		suggestion = l.replace(map[int]string{5: "?"})
+	}
+	l.warn(LintDaysOr, 5, suggestion,
+		"Both the day of month and the day of week are restricted, so the job runs on the days that match either of them, not both")
+}
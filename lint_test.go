@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		spec     string
+		parser   Parser
+		expected []LintWarning
+	}{
+		{"0 5 * * *", standardParser, nil},
+		{"0 */15 * * * *", defaultParser, nil},
+		{"@every 7m", defaultParser, nil},
+		{"0 0 12 1 * *", defaultParser, nil},
+		{"0 0 12 * * MON", defaultParser, nil},
+
+		{"* 5 * * *", standardParser, []LintWarning{
+			{Check: LintEveryTick, Field: "minute", Index: 0, Start: 0, End: 1, Suggestion: "0 5 * * *"},
+		}},
+		{"0 * 5 * * *", defaultParser, []LintWarning{
+			{Check: LintEveryTick, Field: "minute", Index: 1, Start: 2, End: 3, Suggestion: "0 0 5 * * *"},
+		}},
+		{"TZ=UTC * * 5 * * *", defaultParser, []LintWarning{
+			{Check: LintEveryTick, Field: "minute", Index: 1, Start: 9, End: 10, Suggestion: "TZ=UTC 0 0 5 * * *"},
+		}},
+		{"* 30 * * * *", defaultParser, []LintWarning{
+			{Check: LintEveryTick, Field: "second", Index: 0, Start: 0, End: 1, Suggestion: "0 30 * * * *"},
+		}},
+		{"0 */7 * * * *", defaultParser, []LintWarning{
+			{Check: LintUnevenStep, Field: "minute", Index: 1, Start: 2, End: 5, Suggestion: "@every 7m"},
+		}},
+		{"3/7 * * * *", standardParser, []LintWarning{
+			{Check: LintUnevenStep, Field: "minute", Index: 0, Start: 0, End: 3, Suggestion: "@every 7m"},
+		}},
+		{"0 0 */5 * * MON-FRI", defaultParser, []LintWarning{
+			{Check: LintUnevenStep, Field: "hour", Index: 2, Start: 4, End: 7},
+		}},
+		{"0 0 0 1 */5 *", defaultParser, []LintWarning{
+			{Check: LintUnevenStep, Field: "month", Index: 4, Start: 8, End: 11},
+		}},
+		{"0 0 9 1-7 * MON", defaultParser, []LintWarning{
+			{Check: LintDaysOr, Field: "dow", Index: 5, Start: 12, End: 15, Suggestion: "0 0 9 ? * MON#1"},
+		}},
+		{"0 0 9 13 * FRI", defaultParser, []LintWarning{
+			{Check: LintDaysOr, Field: "dow", Index: 5, Start: 11, End: 14, Suggestion: "0 0 9 13 * ?"},
+		}},
+		{"0 0 9 L * MON-FRI", defaultParser, []LintWarning{
+			{Check: LintDaysOr, Field: "dow", Index: 5, Start: 10, End: 17, Suggestion: "0 0 9 L * ?"},
+		}},
+	}
+
+	for _, c := range tests {
+		actual, err := c.parser.Lint(c.spec)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.spec, err)
+			continue
+		}
+		for i := range actual {
+			if actual[i].Message == "" {
+				t.Errorf("%s: expected an explanation in %+v", c.spec, actual[i])
+			}
+			actual[i].Message = ""
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: (expected) %+v != %+v (actual)", c.spec, c.expected, actual)
+		}
+	}
+}
+
+func TestLintMessage(t *testing.T) {
+	warnings, _ := Lint("0 */7 * * * *")
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "matches up to 56, and then 0 again, 4 later") {
+		t.Errorf("unexpected warnings %+v", warnings)
+	}
+}
+
+func TestLintParseError(t *testing.T) {
+	if _, err := Lint("0 60 * * * *"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
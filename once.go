@@ -0,0 +1,45 @@
+package cron
+
+import "time"
+
+// OnceSchedule represents a one-shot duty cycle, e.g. "At 9am on December 1st, 2026".
+// Once its time has passed, Next returns the zero time and the schedule is finished.
+type OnceSchedule struct {
+	Time time.Time
+}
+
+// At returns a crontab Schedule that activates once, at the given time.
+// Any fields less than a Second are truncated.
+func At(t time.Time) OnceSchedule {
+	return OnceSchedule{
+		Time: t.Truncate(time.Second),
+	}
+}
+
+// Next returns the activation time if it is later than the given time, or the
+// zero time if the schedule has already activated.
+func (schedule OnceSchedule) Next(t time.Time) time.Time {
+	if !schedule.Time.After(t) {
+		return time.Time{}
+	}
+	return schedule.Time.In(t.Location())
+}
+
+// Prev returns the activation time if it is earlier than the given time, or the
+// zero time if the schedule has yet to activate.
+func (schedule OnceSchedule) Prev(t time.Time) time.Time {
+	if !schedule.Time.Before(t) {
+		return time.Time{}
+	}
+	return schedule.Time.In(t.Location())
+}
+
+// Matches reports whether the schedule activates at the given time.
+func (schedule OnceSchedule) Matches(t time.Time) bool {
+	return schedule.Time.Equal(t)
+}
+
+// String returns the spec of the schedule, as in "@at 2026-12-01T09:00:00Z".
+func (schedule OnceSchedule) String() string {
+	return "@at " + schedule.Time.Format(time.RFC3339)
+}
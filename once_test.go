@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnceNext(t *testing.T) {
+	tests := []struct {
+		time     string
+		at       string
+		expected string
+	}{
+		// Before the activation time
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 15:00 2012", "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 14:59:59 2012", "Mon Jul 9 15:00 2012", "Mon Jul 9 15:00 2012"},
+		{"Mon Dec 31 23:59:45 2012", "Tue Jan 1 00:00:00 2013", "Tue Jan 1 00:00:00 2013"},
+
+		// At or after the activation time
+		{"Mon Jul 9 15:00 2012", "Mon Jul 9 15:00 2012", ""},
+		{"Mon Jul 9 15:00:01 2012", "Mon Jul 9 15:00 2012", ""},
+		{"Tue Jan 1 00:00:00 2013", "Mon Jul 9 15:00 2012", ""},
+
+		// Truncate to the second
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 15:00:00.005 2012", "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 15:00:00.001 2012", "Mon Jul 9 15:00:00.005 2012", ""},
+	}
+
+	for _, c := range tests {
+		actual := At(getTime(c.at)).Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.at, expected, actual)
+		}
+	}
+}
+
+func TestOncePrev(t *testing.T) {
+	tests := []struct {
+		time     string
+		at       string
+		expected string
+	}{
+		{"Mon Jul 9 15:00:01 2012", "Mon Jul 9 15:00 2012", "Mon Jul 9 15:00 2012"},
+		{"Tue Jan 1 00:00:00 2013", "Mon Jul 9 15:00 2012", "Mon Jul 9 15:00 2012"},
+		{"Mon Jul 9 15:00 2012", "Mon Jul 9 15:00 2012", ""},
+		{"Mon Jul 9 14:45 2012", "Mon Jul 9 15:00 2012", ""},
+	}
+
+	for _, c := range tests {
+		actual := At(getTime(c.at)).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.at, expected, actual)
+		}
+	}
+}
+
+func TestOnceMatches(t *testing.T) {
+	at := time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC)
+	if !At(at).Matches(at.In(time.FixedZone("CET", 3600))) {
+		t.Errorf("%v does not match itself", at)
+	}
+	if At(at).Matches(at.Add(time.Second)) {
+		t.Errorf("%v matches a second later", at)
+	}
+}
+
+func TestOnceNextLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC)
+	actual := At(at).Next(at.Add(-time.Hour).In(tokyo))
+	if actual.Location() != tokyo || !actual.Equal(at) {
+		t.Errorf("(expected) %v != %v (actual)", at.In(tokyo), actual)
+	}
+}
@@ -2,10 +2,12 @@ package cron
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // Configuration options for creating a parser. Most options specify which
@@ -23,6 +25,7 @@ const (
 	Dow                                 // Day of week field, default *
 	DowOptional                         // Optional day of week field, default *
 	Descriptor                          // Allow descriptors such as @monthly, @weekly, etc.
+	Year                                // Optional year field, default *
 )
 
 var places = []ParseOption{
@@ -32,6 +35,7 @@ var places = []ParseOption{
 	Dom,
 	Month,
 	Dow,
+	Year,
 }
 
 var defaults = []string{
@@ -41,12 +45,14 @@ var defaults = []string{
 	"*",
 	"*",
 	"*",
+	"*",
 }
 
 // A custom Parser that can be configured.
 type Parser struct {
 	options   ParseOption
 	optionals int
+	hashKey   string
 }
 
 // Creates a custom Parser with custom options.
@@ -62,6 +68,10 @@ type Parser struct {
 //  // Same as above, just makes Dow optional
 // specParser := NewParser(Dom | Month | DowOptional)
 // sched, err := specParser.Parse("15 */3")
+//
+//  // Quartz-compatible parser, with an optional year field
+// quartzParser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+// sched, err := quartzParser.Parse("0 0 12 * * ? 2026-2028")
 
 func NewParser(options ParseOption) Parser {
 	optionals := 0
@@ -69,18 +79,60 @@ func NewParser(options ParseOption) Parser {
 		options |= Dow
 		optionals++
 	}
-	return Parser{options, optionals}
+	if options&Year > 0 {
+		optionals++
+	}
+	return Parser{options, optionals, ""}
+}
+
+// WithHashKey returns a copy of the parser that uses the given key, such as
+// the name of a job, to choose the values of "H" fields.
+func (p Parser) WithHashKey(key string) Parser {
+	p.hashKey = key
+	return p
+}
+
+// fieldHash returns the hash used to choose the values of "H" in the field in
+// the given place: a stable hash of the parser's key and the place.
+func (p Parser) fieldHash(place int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(p.hashKey))
+	h.Write([]byte{byte(place)})
+
+	// Mix the bits, so that similar keys give well spread values modulo the
+	// size of a field.
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	return x
 }
 
 // Parse returns a new crontab schedule representing the given spec.
-// It returns a descriptive error if the spec is not valid.
+// It returns a descriptive error if the spec is not valid: a *ParseError.
 // It accepts crontab specs and features configured by NewParser.
 func (p Parser) Parse(spec string) (Schedule, error) {
 	if len(spec) == 0 {
-		return nil, fmt.Errorf("Empty spec string")
+		return nil, inSpec(parseErrorf(ReasonEmpty, "", "Empty spec string"), spec, 0, -1, 0)
+	}
+
+	// Extract the time zone, if present.
+	orig := spec
+	loc, offset, err := extractLocation(spec)
+	if err != nil {
+		return nil, err
 	}
-	if spec[0] == '@' && p.options&Descriptor > 0 {
-		return parseDescriptor(spec)
+	// Skip surrounding whitespace, keeping offset at the start of the rest.
+	rest := spec[offset:]
+	offset += len(rest) - len(strings.TrimLeftFunc(rest, unicode.IsSpace))
+	spec = strings.TrimRightFunc(spec[offset:], unicode.IsSpace)
+
+	if len(spec) > 0 && spec[0] == '@' && p.options&Descriptor > 0 {
+		schedule, err := parseDescriptor(spec, loc)
+		if err != nil {
+			return nil, inSpec(err, orig, offset, -1, 0)
+		}
+		return schedule, nil
 	}
 
 	// Figure out how many fields we need
@@ -93,66 +145,171 @@ func (p Parser) Parse(spec string) (Schedule, error) {
 	min := max - p.optionals
 
 	// Split fields on whitespace
-	fields := strings.Fields(spec)
+	fields, offsets := splitFields(spec)
 
 	// Validate number of fields
 	if count := len(fields); count < min || count > max {
+		var perr *ParseError
 		if min == max {
-			return nil, fmt.Errorf("Expected exactly %d fields, found %d: %s", min, count, spec)
+			perr = parseErrorf(ReasonFieldCount, spec, "Expected exactly %d fields, found %d: %s", min, count, spec)
+		} else {
+			perr = parseErrorf(ReasonFieldCount, spec, "Expected %d to %d fields, found %d: %s", min, max, count, spec)
 		}
-		return nil, fmt.Errorf("Expected %d to %d fields, found %d: %s", min, max, count, spec)
+		return nil, inSpec(perr, orig, offset, -1, 0)
 	}
 
 	// Fill in missing fields
-	fields = expandFields(fields, p.options)
+	fields, index := expandFields(fields, p.options)
 
-	var err error
-	field := func(field string, r bounds) uint64 {
+	// locate completes an error in the field in the given place.
+	locate := func(err error, place int) error {
+		i := index[place]
+		if i < 0 {
+			return inSpec(err, orig, 0, -1, 0)
+		}
+		return inSpec(err, orig, offset+offsets[i], place, i)
+	}
+
+	field := func(place int, r bounds) uint64 {
 		if err != nil {
 			return 0
 		}
 		var bits uint64
-		bits, err = getField(field, r)
+		if bits, err = getField(fields[place], r, p.fieldHash(place)); err != nil {
+			err = locate(err, place)
+		}
+		return bits
+	}
+
+	var domLast, domWeekday, dowLast, dowNth uint64
+	domField := func(place int) uint64 {
+		if err != nil {
+			return 0
+		}
+		var bits uint64
+		if bits, domLast, domWeekday, err = getDomField(fields[place], p.fieldHash(place)); err != nil {
+			err = locate(err, place)
+		}
+		return bits
+	}
+	dowField := func(place int) uint64 {
+		if err != nil {
+			return 0
+		}
+		var bits uint64
+		if bits, dowLast, dowNth, err = getDowField(fields[place], p.fieldHash(place)); err != nil {
+			err = locate(err, place)
+		}
 		return bits
 	}
 
 	var (
-		second     = field(fields[0], seconds)
-		minute     = field(fields[1], minutes)
-		hour       = field(fields[2], hours)
-		dayofmonth = field(fields[3], dom)
-		month      = field(fields[4], months)
-		dayofweek  = field(fields[5], dow)
+		second     = field(0, seconds)
+		minute     = field(1, minutes)
+		hour       = field(2, hours)
+		dayofmonth = domField(3)
+		month      = field(4, months)
+		dayofweek  = dowField(5)
+		year       [3]uint64
 	)
+	if err == nil {
+		if year, err = getYearField(fields[6]); err != nil {
+			err = locate(err, 6)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &SpecSchedule{
-		Second: second,
-		Minute: minute,
-		Hour:   hour,
-		Dom:    dayofmonth,
-		Month:  month,
-		Dow:    dayofweek,
-	}, nil
+	schedule := &SpecSchedule{
+		Second:     second,
+		Minute:     minute,
+		Hour:       hour,
+		Dom:        dayofmonth,
+		Month:      month,
+		Dow:        dayofweek,
+		DomLast:    domLast,
+		DomWeekday: domWeekday,
+		DowLast:    dowLast,
+		DowNth:     dowNth,
+		Year:       year,
+		Location:   loc,
+	}
+	if err := schedule.unsatisfiable(); err != nil {
+		perr := parseErrorf(ReasonUnsatisfiable, spec, "%s: %s", err, spec)
+		return nil, inSpec(perr, orig, offset, -1, 0)
+	}
+	return schedule, nil
+}
+
+// extractLocation returns the time zone given by the "TZ=" or "CRON_TZ=" prefix
+// of spec, if any, and the offset of the rest of the spec.
+func extractLocation(spec string) (*time.Location, int, error) {
+	if !strings.HasPrefix(spec, "TZ=") && !strings.HasPrefix(spec, "CRON_TZ=") {
+		return nil, 0, nil
+	}
+	i := strings.IndexAny(spec, " \t")
+	if i == -1 || strings.TrimSpace(spec[i:]) == "" {
+		return nil, 0, inSpec(parseErrorf(ReasonLocation, spec, "Missing fields after time zone: %s", spec), spec, 0, -1, 0)
+	}
+	eq := strings.Index(spec, "=") + 1
+	name := spec[eq:i]
+	if name == "" {
+		// time.LoadLocation would give UTC.
+		return nil, 0, inSpec(parseErrorf(ReasonLocation, name, "Missing time zone name: %s", spec), spec, eq, -1, 0)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		perr := parseErrorf(ReasonLocation, name, "Provided bad location %s: %s", name, err)
+		perr.Err = err
+		return nil, 0, inSpec(perr, spec, eq, -1, 0)
+	}
+	return loc, len(spec) - len(strings.TrimLeftFunc(spec[i:], unicode.IsSpace)), nil
+}
+
+// splitFields splits spec around whitespace, as strings.Fields, and also
+// returns the offset of each field within spec.
+func splitFields(spec string) ([]string, []int) {
+	var (
+		fields  []string
+		offsets []int
+		start   = -1
+	)
+	for i, r := range spec {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			fields = append(fields, spec[start:i])
+			offsets = append(offsets, start)
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, spec[start:])
+		offsets = append(offsets, start)
+	}
+	return fields, offsets
 }
 
-func expandFields(fields []string, options ParseOption) []string {
+// expandFields returns the field of each place, filling in the defaults of the
+// places that are not given, and the index of each place's field among the
+// given fields, or -1.
+func expandFields(fields []string, options ParseOption) ([]string, []int) {
 	n := 0
 	count := len(fields)
 	expFields := make([]string, len(places))
 	copy(expFields, defaults)
+	index := make([]int, len(places))
 	for i, place := range places {
-		if options&place > 0 {
+		index[i] = -1
+		if options&place > 0 && n < count {
 			expFields[i] = fields[n]
+			index[i] = n
 			n++
 		}
-		if n == count {
-			break
-		}
 	}
-	return expFields
+	return expFields, index
 }
 
 var standardParser = NewParser(
@@ -187,24 +344,206 @@ func Parse(spec string) (Schedule, error) {
 
 // getField returns an Int with the bits set representing all of the times that
 // the field represents or error parsing field value.  A "field" is a comma-separated
-// list of "ranges".
-func getField(field string, r bounds) (uint64, error) {
+// list of "ranges". The hash chooses the values of "H" ranges.
+func getField(field string, r bounds, hash uint64) (uint64, error) {
 	var bits uint64
-	ranges := strings.FieldsFunc(field, func(r rune) bool { return r == ',' })
-	for _, expr := range ranges {
-		bit, err := getRange(expr, r)
+	err := eachRange(field, func(expr string) error {
+		bit, err := getRange(expr, r, hash)
+		bits |= bit
+		return err
+	})
+	return bits, err
+}
+
+// eachRange calls f with each of the comma-separated ranges of field, and
+// returns the first error, with its offsets moved to within the field.
+func eachRange(field string, f func(expr string) error) error {
+	offset := 0
+	for _, expr := range strings.Split(field, ",") {
+		if expr != "" {
+			if err := f(expr); err != nil {
+				return shift(err, offset)
+			}
+		}
+		offset += len(expr) + 1
+	}
+	return nil
+}
+
+// getYearField is getField for the year field, whose values do not fit in a
+// uint64. It returns the years as in SpecSchedule: no bits set means any year.
+func getYearField(field string) ([3]uint64, error) {
+	var (
+		bits [3]uint64
+		any  bool
+	)
+	err := eachRange(field, func(expr string) error {
+		start, end, step, star, err := parseRange(expr, years)
 		if err != nil {
-			return bits, err
+			return err
 		}
-		bits |= bit
+		if star && step == 1 {
+			any = true
+		}
+		for y := start; y <= end; y += step {
+			i := y - years.min
+			bits[i/64] |= 1 << (i % 64)
+		}
+		return nil
+	})
+	if err != nil || any {
+		return [3]uint64{}, err
 	}
 	return bits, nil
 }
 
+// getDomField is getField for the day of month field, which may also contain
+// the expressions:
+//
+//	"L" [ "-" number ] | number "W" | "LW"
+//
+// for the last day (or the nth-to-last day), the weekday nearest to the given
+// day, and the last weekday of the month. It returns the bits of the ranges,
+// and of the last days and nearest weekdays as in SpecSchedule.
+func getDomField(field string, hash uint64) (uint64, uint64, uint64, error) {
+	var bits, last, weekday uint64
+	err := eachRange(field, func(expr string) error {
+		upper := strings.ToUpper(expr)
+		switch {
+		case upper == "L":
+			last |= 1
+		case strings.HasPrefix(upper, "L-"):
+			n, err := mustParseInt(expr[2:])
+			if err != nil {
+				return shift(err, 2)
+			}
+			if n > dom.max-1 {
+				return parseErrorf(ReasonAboveMaximum, expr, "Offset from last day (%d) above maximum (%d): %s", n, dom.max-1, expr)
+			}
+			last |= 1 << n
+		case upper == "LW":
+			weekday |= 1
+		case strings.HasSuffix(upper, "W"):
+			n, err := mustParseInt(expr[:len(expr)-1])
+			if err != nil {
+				return err
+			}
+			if n < dom.min {
+				return parseErrorf(ReasonBelowMinimum, expr, "Day of month (%d) outside of %d-%d: %s", n, dom.min, dom.max, expr)
+			}
+			if n > dom.max {
+				return parseErrorf(ReasonAboveMaximum, expr, "Day of month (%d) outside of %d-%d: %s", n, dom.min, dom.max, expr)
+			}
+			weekday |= 1 << n
+		default:
+			bit, err := getRange(expr, dom, hash)
+			if err != nil {
+				return err
+			}
+			bits |= bit
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return bits, last, weekday, nil
+}
+
+// getDowField is getField for the day of week field, which may also contain
+// the expressions:
+//
+//	day "L" | day "#" number
+//
+// for the last given day of week of the month, and the nth given day of week
+// of the month. It returns the bits of the ranges, and of the last and nth days
+// of week as in SpecSchedule.
+func getDowField(field string, hash uint64) (uint64, uint64, uint64, error) {
+	var bits, last, nth uint64
+	err := eachRange(field, func(expr string) error {
+		switch {
+		case strings.HasSuffix(strings.ToUpper(expr), "L"):
+			day, err := parseDow(expr[:len(expr)-1], expr)
+			if err != nil {
+				return err
+			}
+			last |= 1 << day
+		case strings.Contains(expr, "#"):
+			dayAndN := strings.Split(expr, "#")
+			if len(dayAndN) != 2 {
+				return parseErrorf(ReasonSyntax, expr, "Too many hashes: %s", expr)
+			}
+			day, err := parseDow(dayAndN[0], expr)
+			if err != nil {
+				return err
+			}
+			n, err := mustParseInt(dayAndN[1])
+			if err != nil {
+				return shift(err, len(dayAndN[0])+1)
+			}
+			if n < 1 {
+				return parseErrorf(ReasonBelowMinimum, expr, "Week of month (%d) outside of 1-5: %s", n, expr)
+			}
+			if n > 5 {
+				return parseErrorf(ReasonAboveMaximum, expr, "Week of month (%d) outside of 1-5: %s", n, expr)
+			}
+			nth |= 1 << (7*(n-1) + day)
+		default:
+			bit, err := getRange(expr, dow, hash)
+			if err != nil {
+				return err
+			}
+			bits |= bit
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return bits, last, nth, nil
+}
+
+// parseDow returns the (possibly-named) day of week in day, at the start of
+// expr.
+func parseDow(day, expr string) (uint, error) {
+	n, err := parseIntOrName(day, dow.names)
+	if err != nil {
+		return 0, err
+	}
+	if n > dow.max {
+		return 0, parseErrorf(ReasonAboveMaximum, day, "Day of week (%d) above maximum (%d): %s", n, dow.max, expr)
+	}
+	return n, nil
+}
+
 // getRange returns the bits indicated by the given expression:
-//   number | number "-" number [ "/" number ]
-// or error parsing range.
-func getRange(expr string, r bounds) (uint64, error) {
+//
+//	number | number "-" number [ "/" number ]
+//
+// or a hash expression (see getHashRange), or error parsing range.
+func getRange(expr string, r bounds, hash uint64) (uint64, error) {
+	if strings.HasPrefix(expr, "H") {
+		return getHashRange(expr, r, hash)
+	}
+
+	start, end, step, star, err := parseRange(expr, r)
+	if err != nil {
+		return 0, err
+	}
+	var extra uint64
+	if star {
+		extra = starBit
+	}
+	// fmt.Println("extra: ", extra)
+	// fmt.Printf("min: %v, max: %v, step: %v\n\n ", start, end, step)
+
+	return getBits(start, end, step) | extra, nil
+}
+
+// parseRange returns the first and last values and the step of the range given
+// by expr, as for getRange, and whether it is "*" or "?". It returns an error if
+// the range is not within the bounds.
+func parseRange(expr string, r bounds) (uint, uint, uint, bool, error) {
 	var (
 		start, end, step uint
 		rangeAndStep     = strings.Split(expr, "/")
@@ -213,7 +552,7 @@ func getRange(expr string, r bounds) (uint64, error) {
 		err              error
 	)
 
-	var extra uint64
+	var star bool
 	// fmt.Println("expr: ", expr)
 	// fmt.Println("bounds: ", r)
 	// fmt.Println("rangeAndStep: ", rangeAndStep)
@@ -221,11 +560,11 @@ func getRange(expr string, r bounds) (uint64, error) {
 	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
 		start = r.min
 		end = r.max
-		extra = starBit
+		star = true
 	} else {
 		start, err = parseIntOrName(lowAndHigh[0], r.names)
 		if err != nil {
-			return 0, err
+			return 0, 0, 0, false, err
 		}
 		// fmt.Println("start: ", start)
 		// fmt.Println("len: ", len(lowAndHigh))
@@ -235,10 +574,10 @@ func getRange(expr string, r bounds) (uint64, error) {
 		case 2:
 			end, err = parseIntOrName(lowAndHigh[1], r.names)
 			if err != nil {
-				return 0, err
+				return 0, 0, 0, false, shift(err, len(lowAndHigh[0])+1)
 			}
 		default:
-			return 0, fmt.Errorf("Too many hyphens: %s", expr)
+			return 0, 0, 0, false, parseErrorf(ReasonSyntax, expr, "Too many hyphens: %s", expr)
 		}
 	}
 
@@ -248,7 +587,7 @@ func getRange(expr string, r bounds) (uint64, error) {
 	case 2:
 		step, err = mustParseInt(rangeAndStep[1])
 		if err != nil {
-			return 0, err
+			return 0, 0, 0, false, shift(err, len(rangeAndStep[0])+1)
 		}
 
 		// Special handling: "N/step" means "N-max/step".
@@ -256,25 +595,92 @@ func getRange(expr string, r bounds) (uint64, error) {
 			end = r.max
 		}
 	default:
-		return 0, fmt.Errorf("Too many slashes: %s", expr)
+		return 0, 0, 0, false, parseErrorf(ReasonSyntax, expr, "Too many slashes: %s", expr)
 	}
 
 	if start < r.min {
-		return 0, fmt.Errorf("Beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+		return 0, 0, 0, false, parseErrorf(ReasonBelowMinimum, expr, "Beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
 	}
 	if end > r.max {
-		return 0, fmt.Errorf("End of range (%d) above maximum (%d): %s", end, r.max, expr)
+		return 0, 0, 0, false, parseErrorf(ReasonAboveMaximum, expr, "End of range (%d) above maximum (%d): %s", end, r.max, expr)
 	}
 	if start > end {
-		return 0, fmt.Errorf("Beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+		return 0, 0, 0, false, parseErrorf(ReasonReversedRange, expr, "Beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
 	}
 	if step == 0 {
-		return 0, fmt.Errorf("Step of range should be a positive number: %s", expr)
+		return 0, 0, 0, false, parseErrorf(ReasonZeroStep, expr, "Step of range should be a positive number: %s", expr)
 	}
-	// fmt.Println("extra: ", extra)
-	// fmt.Printf("min: %v, max: %v, step: %v\n\n ", start, end, step)
+	return start, end, step, star, nil
+}
 
-	return getBits(start, end, step) | extra, nil
+// getHashRange returns the bits indicated by the given hash expression:
+//
+//	"H" [ "(" number "-" number ")" ] [ "/" number ]
+//
+// or error parsing range. "H" stands for a single value within the range (the
+// whole field by default), chosen by the hash. With a step, it stands for the
+// values at that interval, starting from an offset chosen by the hash. In the
+// day of month field, the default range is 1-28, so that it matches every month.
+func getHashRange(expr string, r bounds, hash uint64) (uint64, error) {
+	var (
+		start, end, step uint
+		rangeAndStep     = strings.Split(expr, "/")
+		err              error
+	)
+
+	start, end = r.min, r.max
+	if r.min == dom.min && r.max == dom.max {
+		end = 28
+	}
+	if rng := rangeAndStep[0][1:]; rng != "" {
+		if !strings.HasPrefix(rng, "(") || !strings.HasSuffix(rng, ")") {
+			return 0, parseErrorf(ReasonSyntax, expr, "Failed to parse hash range: %s", expr)
+		}
+		lowAndHigh := strings.Split(rng[1:len(rng)-1], "-")
+		if len(lowAndHigh) != 2 {
+			return 0, parseErrorf(ReasonSyntax, expr, "Failed to parse hash range: %s", expr)
+		}
+		// The low end follows "H(".
+		if start, err = parseIntOrName(lowAndHigh[0], r.names); err != nil {
+			return 0, shift(err, 2)
+		}
+		if end, err = parseIntOrName(lowAndHigh[1], r.names); err != nil {
+			return 0, shift(err, 2+len(lowAndHigh[0])+1)
+		}
+	}
+
+	switch len(rangeAndStep) {
+	case 1:
+	case 2:
+		step, err = mustParseInt(rangeAndStep[1])
+		if err != nil {
+			return 0, shift(err, len(rangeAndStep[0])+1)
+		}
+		if step == 0 {
+			return 0, parseErrorf(ReasonZeroStep, expr, "Step of range should be a positive number: %s", expr)
+		}
+	default:
+		return 0, parseErrorf(ReasonSyntax, expr, "Too many slashes: %s", expr)
+	}
+
+	if start < r.min {
+		return 0, parseErrorf(ReasonBelowMinimum, expr, "Beginning of range (%d) below minimum (%d): %s", start, r.min, expr)
+	}
+	if end > r.max {
+		return 0, parseErrorf(ReasonAboveMaximum, expr, "End of range (%d) above maximum (%d): %s", end, r.max, expr)
+	}
+	if start > end {
+		return 0, parseErrorf(ReasonReversedRange, expr, "Beginning of range (%d) beyond end of range (%d): %s", start, end, expr)
+	}
+
+	span := end - start + 1
+	if step == 0 {
+		return 1 << (start + uint(hash%uint64(span))), nil
+	}
+	if step < span {
+		span = step
+	}
+	return getBits(start+uint(hash%uint64(span)), end, step), nil
 }
 
 // parseIntOrName returns the (possibly-named) integer contained in expr.
@@ -293,10 +699,12 @@ func parseIntOrName(expr string, names map[string]uint) (uint, error) {
 func mustParseInt(expr string) (uint, error) {
 	num, err := strconv.Atoi(expr)
 	if err != nil {
-		return 0, fmt.Errorf("Failed to parse int from %s: %s", expr, err)
+		perr := parseErrorf(ReasonNotANumber, expr, "Failed to parse int from %s: %s", expr, err)
+		perr.Err = err
+		return 0, perr
 	}
 	if num < 0 {
-		return 0, fmt.Errorf("Negative number (%d) not allowed: %s", num, expr)
+		return 0, parseErrorf(ReasonNegative, expr, "Negative number (%d) not allowed: %s", num, expr)
 	}
 
 	return uint(num), nil
@@ -329,68 +737,100 @@ func all(r bounds) uint64 {
 }
 
 // parseDescriptor returns a predefined schedule for the expression, or error if none matches.
-func parseDescriptor(descriptor string) (Schedule, error) {
+// Schedules built from crontab fields are evaluated in loc, if it is not nil.
+func parseDescriptor(descriptor string, loc *time.Location) (Schedule, error) {
 	switch descriptor {
 	case "@yearly", "@annually":
 		return &SpecSchedule{
-			Second: 1 << seconds.min,
-			Minute: 1 << minutes.min,
-			Hour:   1 << hours.min,
-			Dom:    1 << dom.min,
-			Month:  1 << months.min,
-			Dow:    all(dow),
+			Second:   1 << seconds.min,
+			Minute:   1 << minutes.min,
+			Hour:     1 << hours.min,
+			Dom:      1 << dom.min,
+			Month:    1 << months.min,
+			Dow:      all(dow),
+			Location: loc,
 		}, nil
 
 	case "@monthly":
 		return &SpecSchedule{
-			Second: 1 << seconds.min,
-			Minute: 1 << minutes.min,
-			Hour:   1 << hours.min,
-			Dom:    1 << dom.min,
-			Month:  all(months),
-			Dow:    all(dow),
+			Second:   1 << seconds.min,
+			Minute:   1 << minutes.min,
+			Hour:     1 << hours.min,
+			Dom:      1 << dom.min,
+			Month:    all(months),
+			Dow:      all(dow),
+			Location: loc,
 		}, nil
 
 	case "@weekly":
 		return &SpecSchedule{
-			Second: 1 << seconds.min,
-			Minute: 1 << minutes.min,
-			Hour:   1 << hours.min,
-			Dom:    all(dom),
-			Month:  all(months),
-			Dow:    1 << dow.min,
+			Second:   1 << seconds.min,
+			Minute:   1 << minutes.min,
+			Hour:     1 << hours.min,
+			Dom:      all(dom),
+			Month:    all(months),
+			Dow:      1 << dow.min,
+			Location: loc,
 		}, nil
 
 	case "@daily", "@midnight":
 		return &SpecSchedule{
-			Second: 1 << seconds.min,
-			Minute: 1 << minutes.min,
-			Hour:   1 << hours.min,
-			Dom:    all(dom),
-			Month:  all(months),
-			Dow:    all(dow),
+			Second:   1 << seconds.min,
+			Minute:   1 << minutes.min,
+			Hour:     1 << hours.min,
+			Dom:      all(dom),
+			Month:    all(months),
+			Dow:      all(dow),
+			Location: loc,
 		}, nil
 
 	case "@hourly":
 		return &SpecSchedule{
-			Second: 1 << seconds.min,
-			Minute: 1 << minutes.min,
-			Hour:   all(hours),
-			Dom:    all(dom),
-			Month:  all(months),
-			Dow:    all(dow),
+			Second:   1 << seconds.min,
+			Minute:   1 << minutes.min,
+			Hour:     all(hours),
+			Dom:      all(dom),
+			Month:    all(months),
+			Dow:      all(dow),
+			Location: loc,
 		}, nil
 	}
 
-	const every = "@every "
+	const every, from = "@every ", " from "
 	if strings.HasPrefix(descriptor, every) {
-		duration, err := time.ParseDuration(descriptor[len(every):])
+		value, anchor := descriptor[len(every):], ""
+		if i := strings.Index(value, from); i >= 0 {
+			value, anchor = value[:i], value[i+len(from):]
+		}
+		duration, err := time.ParseDuration(value)
 		fmt.Println("parse duration: ", duration)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse duration %s: %s", descriptor, err)
+			perr := parseErrorf(ReasonDuration, value, "Failed to parse duration %s: %s", descriptor, err)
+			perr.Err = err
+			return nil, shift(perr, len(every))
+		}
+		if anchor == "" {
+			return Every(duration), nil
+		}
+		t, err := time.Parse(time.RFC3339, anchor)
+		if err != nil {
+			perr := parseErrorf(ReasonTime, anchor, "Failed to parse time %s: %s", descriptor, err)
+			perr.Err = err
+			return nil, shift(perr, len(descriptor)-len(anchor))
+		}
+		return EveryFrom(t, duration), nil
+	}
+
+	const at = "@at "
+	if strings.HasPrefix(descriptor, at) {
+		t, err := time.Parse(time.RFC3339, descriptor[len(at):])
+		if err != nil {
+			perr := parseErrorf(ReasonTime, descriptor[len(at):], "Failed to parse time %s: %s", descriptor, err)
+			perr.Err = err
+			return nil, shift(perr, len(at))
 		}
-		return Every(duration), nil
+		return At(t), nil
 	}
 
-	return nil, fmt.Errorf("Unrecognized descriptor: %s", descriptor)
+	return nil, parseErrorf(ReasonDescriptor, descriptor, "Unrecognized descriptor: %s", descriptor)
 }
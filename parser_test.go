@@ -1,7 +1,10 @@
 package cron
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -42,7 +45,7 @@ func TestRange(t *testing.T) {
 	}
 
 	for _, c := range ranges {
-		actual, err := getRange(c.expr, bounds{c.min, c.max, nil})
+		actual, err := getRange(c.expr, bounds{c.min, c.max, nil}, 0)
 		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
 			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
 		}
@@ -55,6 +58,83 @@ func TestRange(t *testing.T) {
 	}
 }
 
+func TestHashRange(t *testing.T) {
+	zero := uint64(0)
+	ranges := []struct {
+		expr     string
+		r        bounds
+		hash     uint64
+		expected uint64
+		err      string
+	}{
+		{"H", minutes, 7, 1 << 7, ""},
+		{"H", minutes, 67, 1 << 7, ""},
+		{"H", hours, 30, 1 << 6, ""},
+		{"H", dom, 30, 1 << 3, ""},
+		{"H", dow, 9, 1 << 2, ""},
+
+		{"H/15", minutes, 7, 1<<7 | 1<<22 | 1<<37 | 1<<52, ""},
+		{"H/2", hours, 7, 0xaaaaaa, ""},
+		{"H(0-29)", minutes, 37, 1 << 7, ""},
+		{"H(10-12)", minutes, 7, 1 << 11, ""},
+		{"H(mon-fri)", dow, 7, 1 << 3, ""},
+		{"H(0-29)/10", minutes, 7, 1<<7 | 1<<17 | 1<<27, ""},
+		{"H(0-5)/10", minutes, 7, 1 << 1, ""},
+
+		{"H(0-29", minutes, 0, zero, "Failed to parse hash range"},
+		{"H(0)", minutes, 0, zero, "Failed to parse hash range"},
+		{"H(a-b)", minutes, 0, zero, "Failed to parse int from"},
+		{"H(0-60)", minutes, 0, zero, "above maximum"},
+		{"H(0-5)", dom, 0, zero, "below minimum"},
+		{"H(5-2)", minutes, 0, zero, "beyond end of range"},
+		{"H/0", minutes, 0, zero, "should be a positive number"},
+		{"H//2", minutes, 0, zero, "Too many slashes"},
+	}
+
+	for _, c := range ranges {
+		actual, err := getRange(c.expr, c.r, c.hash)
+		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+		}
+		if actual != c.expected {
+			t.Errorf("%s => expected %b, got %b", c.expr, c.expected, actual)
+		}
+	}
+}
+
+// Test that H fields are stable for a key, and spread across keys and fields.
+func TestParseHash(t *testing.T) {
+	parse := func(key string) *SpecSchedule {
+		s, err := defaultParser.WithHashKey(key).Parse("H H * * * *")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.(*SpecSchedule)
+	}
+
+	if !reflect.DeepEqual(parse("tenant-1"), parse("tenant-1")) {
+		t.Error("expected the same schedule for the same key")
+	}
+	minutes := map[uint64]bool{}
+	sameSecond := 0
+	for i := 0; i < 1000; i++ {
+		s := parse(fmt.Sprintf("tenant-%d", i))
+		minutes[s.Minute] = true
+		if s.Second == s.Minute {
+			sameSecond++
+		}
+	}
+	if len(minutes) < 55 {
+		t.Errorf("expected keys spread over the minutes, got %d distinct minutes", len(minutes))
+	}
+	if sameSecond > 100 {
+		t.Errorf("expected seconds independent of minutes, got %d equal", sameSecond)
+	}
+}
+
 func TestField(t *testing.T) {
 	fields := []struct {
 		expr     string
@@ -68,7 +148,7 @@ func TestField(t *testing.T) {
 	}
 
 	for _, c := range fields {
-		actual, _ := getField(c.expr, bounds{c.min, c.max, nil})
+		actual, _ := getField(c.expr, bounds{c.min, c.max, nil}, 0)
 		if actual != c.expected {
 			t.Errorf("%s => expected %d, got %d", c.expr, c.expected, actual)
 		}
@@ -145,6 +225,13 @@ func TestParse(t *testing.T) {
 			expr: "@every Xm",
 			err:  "Failed to parse duration",
 		},
+		{
+			expr: "@every 5m from 2026-12-01T09:00:00Z",
+			expected: ConstantDelaySchedule{
+				Delay:  time.Duration(5) * time.Minute,
+				Anchor: time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC),
+			},
+		},
 		{
 			expr: "@yearly",
 			expected: &SpecSchedule{
@@ -167,6 +254,58 @@ func TestParse(t *testing.T) {
 				Dow:    all(dow),
 			},
 		},
+		{
+			expr:     "@at 2026-12-01T09:00:00Z",
+			expected: OnceSchedule{Time: time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "@at 2026-12-01 09:00",
+			err:  "Failed to parse time",
+		},
+		{
+			expr: "0 0 0 L-3,LW,15W * ?",
+			expected: &SpecSchedule{
+				Second:     1 << seconds.min,
+				Minute:     1 << minutes.min,
+				Hour:       1 << hours.min,
+				Month:      all(months),
+				Dow:        all(dow),
+				DomLast:    1 << 3,
+				DomWeekday: 1<<0 | 1<<15,
+			},
+		},
+		{
+			expr: "0 0 0 ? * 5L,mon#2",
+			expected: &SpecSchedule{
+				Second:  1 << seconds.min,
+				Minute:  1 << minutes.min,
+				Hour:    1 << hours.min,
+				Dom:     all(dom),
+				Month:   all(months),
+				DowLast: 1 << 5,
+				DowNth:  1 << (7 + 1),
+			},
+		},
+		{
+			expr: "0 0 0 L-31 * ?",
+			err:  "above maximum",
+		},
+		{
+			expr: "0 0 0 32W * ?",
+			err:  "outside of 1-31",
+		},
+		{
+			expr: "0 0 0 ? * 7L",
+			err:  "above maximum",
+		},
+		{
+			expr: "0 0 0 ? * MON#6",
+			err:  "outside of 1-5",
+		},
+		{
+			expr: "0 0 0 ? * 1#2#3",
+			err:  "Too many hashes",
+		},
 		{
 			expr: "@unrecognized",
 			err:  "Unrecognized descriptor",
@@ -195,6 +334,69 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseError(t *testing.T) {
+	entries := []struct {
+		expr       string
+		field      string
+		index      int
+		token      string
+		start, end int
+		reason     ParseReason
+	}{
+		{"0 0 12 1-40 * *", "dom", 3, "1-40", 7, 11, ReasonAboveMaximum},
+		{"0 0,5,99 * * * *", "minute", 1, "99", 6, 8, ReasonAboveMaximum},
+		{"0 0 12 ? * 1-3/x", "dow", 5, "x", 15, 16, ReasonNotANumber},
+		{"0 0 12 * * MON#9", "dow", 5, "MON#9", 11, 16, ReasonAboveMaximum},
+		{"0 0 12 * * 9L", "dow", 5, "9", 11, 12, ReasonAboveMaximum},
+		{"0 0 0 L-x * *", "dom", 3, "x", 8, 9, ReasonNotANumber},
+		{"0 0 0 H(x-9) * *", "dom", 3, "x", 8, 9, ReasonNotANumber},
+		{"0 0 H(5-70) * * *", "hour", 2, "H(5-70)", 4, 11, ReasonAboveMaximum},
+		{"0 0 0 5-3 * *", "dom", 3, "5-3", 6, 9, ReasonReversedRange},
+		{"0 */0 * * * *", "minute", 1, "*/0", 2, 5, ReasonZeroStep},
+		{"0 1--2 * * * *", "minute", 1, "1--2", 2, 6, ReasonSyntax},
+		{"TZ=UTC 0 0 12 * x-3 *", "month", 4, "x", 16, 17, ReasonNotANumber},
+		{"  0 0 0 * * x", "dow", 5, "x", 12, 13, ReasonNotANumber},
+		{"0 0 12 1-40 * *\t ", "dom", 3, "1-40", 7, 11, ReasonAboveMaximum},
+		{"TZ=UTC \t 0 0 12 * x-3 *", "month", 4, "x", 18, 19, ReasonNotANumber},
+		{"  0 0 0 30 Feb ?  ", "", -1, "0 0 0 30 Feb ?", 2, 16, ReasonUnsatisfiable},
+		{"TZ=Mars/Olympus 0 0 * * *", "", -1, "Mars/Olympus", 3, 15, ReasonLocation},
+		{"CRON_TZ=UTC  @every 1x", "", -1, "1x", 20, 22, ReasonDuration},
+		{"@every 1x from 2026-12-01T09:00:00Z", "", -1, "1x", 7, 9, ReasonDuration},
+		{"@every 1h from tomorrow", "", -1, "tomorrow", 15, 23, ReasonTime},
+		{"CRON_TZ=UTC ", "", -1, "CRON_TZ=UTC ", 0, 12, ReasonLocation},
+		{"TZ=UTC\t ", "", -1, "TZ=UTC\t ", 0, 8, ReasonLocation},
+		{"TZ= 0 0 * * *", "", -1, "", 3, 3, ReasonLocation},
+		{"@at tomorrow", "", -1, "tomorrow", 4, 12, ReasonTime},
+		{"@unrecognized", "", -1, "@unrecognized", 0, 13, ReasonDescriptor},
+		{"* * * *", "", -1, "* * * *", 0, 7, ReasonFieldCount},
+		{"   ", "", -1, "", 3, 3, ReasonFieldCount},
+		{"0 0 0 30 Feb ?", "", -1, "0 0 0 30 Feb ?", 0, 14, ReasonUnsatisfiable},
+		{"", "", -1, "", 0, 0, ReasonEmpty},
+	}
+
+	for _, c := range entries {
+		_, err := Parse(c.expr)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s => expected a ParseError, got %v", c.expr, err)
+			continue
+		}
+		if perr.Spec != c.expr || perr.Field != c.field || perr.Index != c.index || perr.Token != c.token ||
+			perr.Start != c.start || perr.End != c.end || perr.Reason != c.reason {
+			t.Errorf("%s => expected %s %d %q [%d:%d] %s, got %s %d %q [%d:%d] %s", c.expr,
+				c.field, c.index, c.token, c.start, c.end, c.reason,
+				perr.Field, perr.Index, perr.Token, perr.Start, perr.End, perr.Reason)
+		}
+		if c.expr[perr.Start:perr.End] != perr.Token {
+			t.Errorf("%s => expected token %q at [%d:%d]", c.expr, perr.Token, perr.Start, perr.End)
+		}
+	}
+
+	if _, err := Parse("0 0 x * * *"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected the error from strconv to be wrapped, got %v", err)
+	}
+}
+
 func TestStandardSpecSchedule(t *testing.T) {
 	entries := []struct {
 		expr     string
@@ -202,12 +404,19 @@ func TestStandardSpecSchedule(t *testing.T) {
 		err      string
 	}{
 		{
-			expr:     "5 * * * *",
-			expected: &SpecSchedule{1 << seconds.min, 1 << 5, all(hours), all(dom), all(months), all(dow)},
+			expr: "5 * * * *",
+			expected: &SpecSchedule{
+				Second: 1 << seconds.min,
+				Minute: 1 << 5,
+				Hour:   all(hours),
+				Dom:    all(dom),
+				Month:  all(months),
+				Dow:    all(dow),
+			},
 		},
 		{
 			expr:     "@every 5m",
-			expected: ConstantDelaySchedule{time.Duration(5) * time.Minute},
+			expected: ConstantDelaySchedule{Delay: time.Duration(5) * time.Minute},
 		},
 		{
 			expr: "5 j * * *",
@@ -232,3 +441,77 @@ func TestStandardSpecSchedule(t *testing.T) {
 		}
 	}
 }
+
+func TestParseYear(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+	entries := []struct {
+		expr     string
+		expected [3]uint64
+		err      string
+	}{
+		{"0 0 12 * * ?", [3]uint64{}, ""},
+		{"0 0 12 * * ? *", [3]uint64{}, ""},
+		{"0 0 12 * * ? 1970", [3]uint64{1}, ""},
+		{"0 0 12 * * ? 2027", [3]uint64{1 << (2027 - 1970)}, ""},
+		{"0 0 12 * * ? 2032-2035", [3]uint64{3 << (2032 - 1970), 3}, ""},
+		{"0 0 12 * * ? 2090,2099", [3]uint64{0, 1 << (2090 - 2034), 1 << (2099 - 2098)}, ""},
+		{"0 0 12 * * ? 1969", [3]uint64{}, "below minimum"},
+		{"0 0 12 * * ? 2100", [3]uint64{}, "above maximum"},
+		{"0 0 12 * * ? 2027 1", [3]uint64{}, "Expected 6 to 7 fields"},
+	}
+
+	for _, c := range entries {
+		actual, err := parser.Parse(c.expr)
+		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if year := actual.(*SpecSchedule).Year; year != c.expected {
+			t.Errorf("%s => expected %b, got %b", c.expr, c.expected, year)
+		}
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	entries := []struct {
+		expr     string
+		location string
+		err      string
+	}{
+		{"* * * * * *", "", ""},
+		{"CRON_TZ=UTC * * * * * *", "UTC", ""},
+		{"TZ=America/New_York 0 5 * * * *", "America/New_York", ""},
+		{"CRON_TZ=Asia/Tokyo @daily", "Asia/Tokyo", ""},
+		{"CRON_TZ=Asia/Tokyo\t@hourly", "Asia/Tokyo", ""},
+		{"CRON_TZ=Bad/Zone * * * * * *", "", "Provided bad location"},
+		{"CRON_TZ=UTC", "", "Missing fields after time zone"},
+		{"CRON_TZ=UTC ", "", "Missing fields after time zone"},
+		{"TZ=UTC\t ", "", "Missing fields after time zone"},
+		{"TZ= * * * * *", "", "Missing time zone name"},
+	}
+
+	for _, c := range entries {
+		actual, err := Parse(c.expr)
+		if len(c.err) != 0 && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s => expected %v, got %v", c.expr, c.err, err)
+		}
+		if len(c.err) == 0 && err != nil {
+			t.Errorf("%s => unexpected error %v", c.expr, err)
+		}
+		if err != nil {
+			continue
+		}
+		var location string
+		if loc := actual.(*SpecSchedule).Location; loc != nil {
+			location = loc.String()
+		}
+		if location != c.location {
+			t.Errorf("%s => expected location %q, got %q", c.expr, c.location, location)
+		}
+	}
+}
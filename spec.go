@@ -2,6 +2,8 @@ package cron
 
 import (
 	"fmt"
+	"math"
+	"math/bits"
 	"time"
 )
 
@@ -9,6 +11,24 @@ import (
 // traditional crontab specification. It is computed initially and stored as bit sets.
 type SpecSchedule struct {
 	Second, Minute, Hour, Dom, Month, Dow uint64
+
+	// Days that depend on the month, in addition to those in Dom and Dow:
+	//   - DomLast has bit n set for the nth-to-last day of the month ("L-n"),
+	//     and bit 0 for the last day ("L").
+	//   - DomWeekday has bit n set for the weekday nearest to day n ("nW"), and
+	//     bit 0 for the last weekday of the month ("LW").
+	//   - DowLast has bit w set for the last weekday w of the month ("wL").
+	//   - DowNth has bit 7*(n-1)+w set for the nth weekday w ("w#n").
+	DomLast, DomWeekday, DowLast, DowNth uint64
+
+	// Year has bit y-1970 set for each year y in which the schedule activates,
+	// from 1970 to 2099. If no bits are set, it activates in any year.
+	Year [3]uint64
+
+	// Location overrides the time zone in which the schedule is evaluated, as
+	// given by a CRON_TZ= or TZ= prefix on the spec. If nil, the schedule is
+	// evaluated in the location of the time passed to Next.
+	Location *time.Location
 }
 
 // bounds provides a range of acceptable values (plus a map of name to value).
@@ -46,145 +66,441 @@ var (
 		"fri": 5,
 		"sat": 6,
 	}}
+	years = bounds{1970, 2099, nil}
 )
 
 const (
 	// Set the top bit if a star was included in the expression.
 	starBit = 1 << 63
+
+	// The number of years after which the Gregorian calendar repeats itself,
+	// days of the week included. A schedule that does not activate within this
+	// many years never does.
+	calendarCycle = 400
 )
 
 // Next returns the next time this schedule is activated, greater than the given
-// time.  If no time can be found to satisfy the schedule, return the zero time.
+// time.  If the schedule never activates again, return the zero time.
+//
+// Daylight saving time transitions are handled the way Vixie cron does:
+//   - Activations whose wall clock time is skipped when the clocks go forward
+//     run once, at the first instant after the gap.
+//   - Activations whose wall clock time is repeated when the clocks go back run
+//     once, at the first occurrence.
+//
+// If the hour field is a wildcard ("*" or "*/n"), the schedule follows the
+// hours as they pass instead: skipped times do not run, and repeated times run
+// during both occurrences.
 func (s *SpecSchedule) Next(t time.Time) time.Time {
-	// General approach:
-	// For Month, Day, Hour, Minute, Second:
-	// Check if the time value matches.  If yes, continue to the next field.
-	// If the field doesn't match the schedule, then increment the field until it matches.
-	// While incrementing the field, a wrap-around brings it back to the beginning
-	// of the field list (since it is necessary to re-verify previous field
-	// values)
+	return s.NextWithin(t, calendarCycle)
+}
+
+// NextWithin returns the next time this schedule is activated, greater than the
+// given time and within the given number of years of it. If there is no such
+// time, it returns the zero time. See Next.
+func (s *SpecSchedule) NextWithin(t time.Time, years int) time.Time {
+	// Convert the given time into the schedule's time zone, if it has one.
+	// The result is converted back into the original time zone before returning.
+	origLocation := t.Location()
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
 
 	// Start at the earliest possible time (the upcoming second).
 	t = t.Add(1*time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
-	fmt.Println("added time: ", t)
+	limit := t.Year() + years
+
+	// Search the wall clock for the next activation within the span of time
+	// that shares t's UTC offset. If the span ends before the activation, carry
+	// on from the zone transition.
+	for {
+		_, offset := t.Zone()
+		start, end := t.ZoneBounds()
+		wall := s.nextWall(wallClock(t), limit)
+		if wall.IsZero() {
+			return time.Time{}
+		}
 
-	// This flag indicates whether a field has been incremented.
-	added := false
+		next := time.Unix(wall.Unix()-int64(offset), 0).In(t.Location())
+		if end.IsZero() || next.Before(end) {
+			if s.Hour&starBit == 0 && repeated(wall, start, offset) {
+				// This time of day already ran before the clocks went back.
+				t = next.Add(1 * time.Second)
+				continue
+			}
+			return next.In(origLocation)
+		}
 
-	// If no time is found within five years, return zero.
-	yearLimit := t.Year() + 5
+		// If the transition skips over the wall clock time, run right after it,
+		// unless the hours are a wildcard.
+		if _, after := end.Zone(); s.Hour&starBit == 0 && after > offset && wall.Unix() < end.Unix()+int64(after) {
+			return end.In(origLocation)
+		}
+		t = end
+	}
+}
 
-WRAP:
-	if t.Year() > yearLimit {
+// nextWall returns the first wall clock time at or after t that satisfies the
+// schedule, in a year no later than limit. Wall clock times are represented in
+// UTC, so that every hour of every day exists exactly once. If there is no such
+// time, it returns the zero time.
+func (s *SpecSchedule) nextWall(t time.Time, limit int) time.Time {
+	// General approach:
+	// For Year, Month, Day, Hour, Minute, Second:
+	// Jump to the next value of the field that matches, using the bitmasks. If
+	// there is none left, move on to the start of the next value of the field
+	// above, and start over (since the fields above need to be re-verified).
+	if s.Second&^starBit == 0 || s.Minute&^starBit == 0 || s.Hour&^starBit == 0 {
 		return time.Time{}
 	}
 
-	// Find the first applicable month.
-	// If it's this month, then do nothing.
-	// fmt.Println("schedule: ", s)
-	// fmt.Println("month: ", t.Month())
-	// fmt.Println("t month: ", uint(t.Month()))
-	// fmt.Printf("%64b: [64b]\n", 1<<uint(t.Month()))
-	// fmt.Printf("%64b: [64b]\n", s.Month)
-	// fmt.Printf("and Month: %v \n\n", 1<<uint(t.Month())&s.Month)
-	for 1<<uint(t.Month())&s.Month == 0 {
-		// If we have to add a month, reset the other parts to 0.
-		// fmt.Println("in month")
-		if !added {
-			added = true
-			// Otherwise, set the date at the beginning (since the current time is irrelevant).
-			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	for t.Year() <= limit {
+		year, month, day := t.Date()
+		hour, minute, second := t.Clock()
+
+		if y := s.nextYear(year); y != year {
+			if y == 0 {
+				return time.Time{}
+			}
+			t = time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		m, ok := nextBit(s.Month, uint(month), months.max)
+		if !ok {
+			t = time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if time.Month(m) != month {
+			t = time.Date(year, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !dayMatches(s, t) {
+			if day = s.nextDay(t); day == 0 {
+				t = time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+			} else {
+				t = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+			}
+			continue
+		}
+
+		h, ok := nextBit(s.Hour, uint(hour), hours.max)
+		if !ok {
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if int(h) != hour {
+			t = time.Date(year, month, day, int(h), 0, 0, 0, time.UTC)
+			continue
+		}
+
+		min, ok := nextBit(s.Minute, uint(minute), minutes.max)
+		if !ok {
+			t = time.Date(year, month, day, hour+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if int(min) != minute {
+			t = time.Date(year, month, day, hour, int(min), 0, 0, time.UTC)
+			continue
 		}
-		t = t.AddDate(0, 1, 0)
-		// fmt.Println("added month: ", t)
 
-		// Wrapped around.
-		if t.Month() == time.January {
-			goto WRAP
+		sec, ok := nextBit(s.Second, uint(second), seconds.max)
+		if !ok {
+			t = time.Date(year, month, day, hour, minute+1, 0, 0, time.UTC)
+			continue
 		}
+		return time.Date(year, month, day, hour, minute, int(sec), 0, time.UTC)
 	}
+	return time.Time{}
+}
+
+// Prev returns the latest time this schedule was activated, before the given
+// time, as Next would have found it: daylight saving time transitions are
+// handled the same way. If the schedule never activated within the 400 years
+// before the given time, it returns the zero time.
+func (s *SpecSchedule) Prev(t time.Time) time.Time {
+	origLocation := t.Location()
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
+
+	// Start at the latest possible time (the previous second).
+	t = t.Add(-1 * time.Nanosecond)
+	t = t.Add(-time.Duration(t.Nanosecond()) * time.Nanosecond)
+	limit := t.Year() - calendarCycle
 
-	// Now get a day in that month.
-	for !dayMatches(s, t) {
-		// fmt.Println("in day")
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	// Search the wall clock for the previous activation within the span of
+	// time that shares t's UTC offset. If the span starts after the activation,
+	// carry on from before the zone transition.
+	for {
+		_, offset := t.Zone()
+		start, _ := t.ZoneBounds()
+		wall := s.prevWall(wallClock(t), limit)
+		if wall.IsZero() {
+			return time.Time{}
 		}
-		t = t.AddDate(0, 0, 1)
-		// fmt.Println("added day: ", t)
 
-		if t.Day() == 1 {
-			goto WRAP
+		prev := time.Unix(wall.Unix()-int64(offset), 0).In(t.Location())
+		if start.IsZero() || !prev.Before(start) {
+			if s.Hour&starBit == 0 && repeated(wall, start, offset) {
+				// This time of day ran before the clocks went back.
+				t = prev.Add(-1 * time.Second)
+				continue
+			}
+			return prev.In(origLocation)
 		}
+
+		// If the transition skipped over the wall clock time, it ran right after it,
+		// unless the hours are a wildcard.
+		if _, before := start.Add(-1 * time.Second).Zone(); s.Hour&starBit == 0 && before < offset && wall.Unix() >= start.Unix()+int64(before) {
+			return start.In(origLocation)
+		}
+		t = start.Add(-1 * time.Second)
 	}
+}
+
+// prevWall returns the last wall clock time at or before t that satisfies the
+// schedule, in a year no earlier than limit, as for nextWall. If there is no
+// such time, it returns the zero time.
+func (s *SpecSchedule) prevWall(t time.Time, limit int) time.Time {
+	// As for nextWall, jump to the previous value of each field that matches,
+	// or else to the end of the previous value of the field above.
+	if s.Second&^starBit == 0 || s.Minute&^starBit == 0 || s.Hour&^starBit == 0 {
+		return time.Time{}
+	}
+
+	for t.Year() >= limit {
+		year, month, day := t.Date()
+		hour, minute, second := t.Clock()
+
+		if y := s.prevYear(year); y != year {
+			if y == 0 {
+				return time.Time{}
+			}
+			t = time.Date(y, time.December, 31, 23, 59, 59, 0, time.UTC)
+			continue
+		}
 
-	// fmt.Println("t hour", uint(t.Hour()))
-	// fmt.Printf("%64b: [64b]\n", 1<<uint(t.Hour()))
-	// fmt.Printf("%64b: [64b]\n", s.Hour)
-	// fmt.Printf("%64b: [64b] \n", 1<<uint(t.Hour())&s.Hour)
-	// fmt.Printf("and Hour: %v \n\n", 1<<uint(t.Hour())&s.Hour)
-	for 1<<uint(t.Hour())&s.Hour == 0 {
-		fmt.Println("in hour")
-		if !added {
-			added = true
-			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+		m, ok := prevBit(s.Month, months.min, uint(month))
+		if !ok {
+			t = time.Date(year, time.January, 1, 0, 0, -1, 0, time.UTC)
+			continue
 		}
-		t = t.Add(1 * time.Hour)
-		fmt.Println("added hour", t)
+		if time.Month(m) != month {
+			t = time.Date(year, time.Month(m)+1, 1, 0, 0, -1, 0, time.UTC)
+			continue
+		}
+
+		if !dayMatches(s, t) {
+			if day = s.prevDay(t); day == 0 {
+				t = time.Date(year, month, 1, 0, 0, -1, 0, time.UTC)
+			} else {
+				t = time.Date(year, month, day, 23, 59, 59, 0, time.UTC)
+			}
+			continue
+		}
+
+		h, ok := prevBit(s.Hour, hours.min, uint(hour))
+		if !ok {
+			t = time.Date(year, month, day, 0, 0, -1, 0, time.UTC)
+			continue
+		}
+		if int(h) != hour {
+			t = time.Date(year, month, day, int(h), 59, 59, 0, time.UTC)
+			continue
+		}
+
+		min, ok := prevBit(s.Minute, minutes.min, uint(minute))
+		if !ok {
+			t = time.Date(year, month, day, hour, 0, -1, 0, time.UTC)
+			continue
+		}
+		if int(min) != minute {
+			t = time.Date(year, month, day, hour, int(min), 59, 0, time.UTC)
+			continue
+		}
+
+		sec, ok := prevBit(s.Second, seconds.min, uint(second))
+		if !ok {
+			t = time.Date(year, month, day, hour, minute, -1, 0, time.UTC)
+			continue
+		}
+		return time.Date(year, month, day, hour, minute, int(sec), 0, time.UTC)
+	}
+	return time.Time{}
+}
 
-		if t.Hour() == 0 {
-			goto WRAP
+// prevBit returns the highest bit set in mask, from bit max down to bit min,
+// not counting the star bit. It returns false if there is none.
+func prevBit(mask uint64, min, max uint) (uint, bool) {
+	mask &^= starBit
+	mask &^= math.MaxUint64 << (max + 1)
+	if mask == 0 {
+		return 0, false
+	}
+	n := uint(63 - bits.LeadingZeros64(mask))
+	return n, n >= min
+}
+
+// prevDay returns the last day of the month of t before the day of t that
+// satisfies the schedule's day restrictions, or 0 if there is none.
+func (s *SpecSchedule) prevDay(t time.Time) int {
+	year, month, day := t.Date()
+	for day > 1 {
+		day--
+		if dayMatches(s, time.Date(year, month, day, 0, 0, 0, 0, time.UTC)) {
+			return day
 		}
 	}
+	return 0
+}
+
+// nextBit returns the lowest bit set in mask, from bit min up to bit max, not
+// counting the star bit. It returns false if there is none.
+func nextBit(mask uint64, min, max uint) (uint, bool) {
+	mask &^= starBit
+	mask &= math.MaxUint64 << min
+	if mask == 0 {
+		return 0, false
+	}
+	n := uint(bits.TrailingZeros64(mask))
+	return n, n <= max
+}
 
-	// fmt.Println("t minute", uint(t.Minute()))
-	// fmt.Printf("%64b: [64b]\n", 1<<uint(t.Minute()))
-	// fmt.Printf("%64b: [64b]\n", s.Minute)
-	// fmt.Printf("%64b: [64b] \n", 1<<uint(t.Minute())&s.Minute)
-	// fmt.Printf("and Minute: %v \n\n", 1<<uint(t.Minute())&s.Minute)
-	for 1<<uint(t.Minute())&s.Minute == 0 {
-		fmt.Println("in minute")
-		if !added {
-			added = true
-			t = t.Truncate(time.Minute)
+// nextDay returns the first day of the month of t after the day of t that
+// satisfies the schedule's day restrictions, or 0 if there is none.
+func (s *SpecSchedule) nextDay(t time.Time) int {
+	year, month, day := t.Date()
+	for last := daysIn(t); day < last; {
+		day++
+		if dayMatches(s, time.Date(year, month, day, 0, 0, 0, 0, time.UTC)) {
+			return day
 		}
-		t = t.Add(1 * time.Minute)
-		// fmt.Println("added minute", t)
+	}
+	return 0
+}
+
+// Satisfiable reports whether the schedule ever activates. Since the calendar
+// repeats itself, this is known from searching a single cycle of it.
+func (s *SpecSchedule) Satisfiable() bool {
+	year := int(years.min)
+	return !s.nextWall(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), year+calendarCycle-1).IsZero()
+}
 
-		if t.Minute() == 0 {
-			goto WRAP
+// unsatisfiable returns an error describing why the schedule never activates,
+// or nil if it does.
+func (s *SpecSchedule) unsatisfiable() error {
+	if s.Satisfiable() {
+		return nil
+	}
+
+	// Days of month that are too late for every month of the schedule.
+	if s.DomLast|s.DomWeekday|s.DowLast|s.DowNth == 0 && s.Dow&starBit > 0 && s.Dom&^starBit != 0 {
+		day, _ := nextBit(s.Dom, dom.min, dom.max)
+		longest := 0
+		for m := months.min; m <= months.max; m++ {
+			// A leap year has the longest months.
+			if n := daysIn(time.Date(2000, time.Month(m), 1, 0, 0, 0, 0, time.UTC)); 1<<m&s.Month > 0 && n > longest {
+				longest = n
+			}
+		}
+		if int(day) > longest {
+			return fmt.Errorf("Day of month %d never occurs in the given months", day)
 		}
 	}
+	if s.Year != [3]uint64{} {
+		return fmt.Errorf("Days never occur in the given years")
+	}
+	return fmt.Errorf("Days never occur in the given months")
+}
 
-	// fmt.Println("t second", uint(t.Second()))
-	// fmt.Printf("%64b: [64b]\n", 1<<uint(t.Second()))
-	// fmt.Printf("%64b: [64b]\n", s.Second)
-	// fmt.Printf("%64b: [64b] \n", 1<<uint(t.Second())&s.Second)
-	// fmt.Printf("and Second: %v \n\n", 1<<uint(t.Second())&s.Second)
-	for 1<<uint(t.Second())&s.Second == 0 {
-		if !added {
-			added = true
-			t = t.Truncate(time.Second)
+// nextYear returns the first year, from y onwards, in which the schedule
+// activates, or 0 if there is none.
+func (s *SpecSchedule) nextYear(y int) int {
+	if s.Year == [3]uint64{} {
+		return y
+	}
+	if y < int(years.min) {
+		y = int(years.min)
+	}
+	for ; y <= int(years.max); y++ {
+		if s.yearMatches(y) {
+			return y
 		}
-		t = t.Add(1 * time.Second)
-		// fmt.Println("added second", t)
+	}
+	return 0
+}
 
-		if t.Second() == 0 {
-			goto WRAP
+// prevYear returns the last year, from y backwards, in which the schedule
+// activates, or 0 if there is none.
+func (s *SpecSchedule) prevYear(y int) int {
+	if s.Year == [3]uint64{} {
+		return y
+	}
+	if y > int(years.max) {
+		y = int(years.max)
+	}
+	for ; y >= int(years.min); y-- {
+		if s.yearMatches(y) {
+			return y
 		}
 	}
+	return 0
+}
+
+// yearMatches returns true if the schedule activates in the year y.
+func (s *SpecSchedule) yearMatches(y int) bool {
+	if s.Year == [3]uint64{} {
+		return true
+	}
+	if y < int(years.min) || y > int(years.max) {
+		return false
+	}
+	i := uint(y) - years.min
+	return 1<<(i%64)&s.Year[i/64] > 0
+}
 
-	fmt.Println("res: ", t)
-	return t
+// wallClock returns the wall clock time shown by t, represented in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// repeated reports whether the wall clock time was already shown before the zone
+// transition at start, which is the case when the clocks went back at start.
+func repeated(wall, start time.Time, offset int) bool {
+	if start.IsZero() {
+		return false
+	}
+	_, before := start.Add(-1 * time.Second).Zone()
+	return before > offset && wall.Unix()-int64(before) < start.Unix()
+}
+
+// Matches reports whether the schedule activates at the given time, testing its
+// wall clock time, in the schedule's time zone if it has one, against each
+// field. Unlike Next, it does not move activations skipped by daylight saving
+// time to after the gap, nor leave out repeated times. Times with a fraction
+// of a second never match.
+func (s *SpecSchedule) Matches(t time.Time) bool {
+	if s.Location != nil {
+		t = t.In(s.Location)
+	}
+	if t.Nanosecond() != 0 || !s.yearMatches(t.Year()) {
+		return false
+	}
+	t = wallClock(t)
+	return 1<<uint(t.Month())&s.Month > 0 && dayMatches(s, t) &&
+		1<<uint(t.Hour())&s.Hour > 0 &&
+		1<<uint(t.Minute())&s.Minute > 0 &&
+		1<<uint(t.Second())&s.Second > 0
 }
 
 // dayMatches returns true if the schedule's day-of-week and day-of-month
 // restrictions are satisfied by the given time.
 func dayMatches(s *SpecSchedule, t time.Time) bool {
 	var (
-		domMatch bool = 1<<uint(t.Day())&s.Dom > 0
-		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0
+		domMatch bool = 1<<uint(t.Day())&s.Dom > 0 || s.domExtensionMatches(t)
+		dowMatch bool = 1<<uint(t.Weekday())&s.Dow > 0 || s.dowExtensionMatches(t)
 	)
 
 	// fmt.Println("t dom", uint(t.Day()))
@@ -209,3 +525,65 @@ func dayMatches(s *SpecSchedule, t time.Time) bool {
 	}
 	return domMatch || dowMatch
 }
+
+// domExtensionMatches returns true if the day of t is one of the schedule's
+// days of month that depend on the month: the last days and nearest weekdays.
+func (s *SpecSchedule) domExtensionMatches(t time.Time) bool {
+	if s.DomLast == 0 && s.DomWeekday == 0 {
+		return false
+	}
+	day, last := t.Day(), daysIn(t)
+	if 1<<uint(last-day)&s.DomLast > 0 {
+		return true
+	}
+	for n := 0; n <= 31; n++ {
+		if 1<<uint(n)&s.DomWeekday == 0 {
+			continue
+		}
+		target := n
+		if n == 0 {
+			target = last
+		}
+		if target <= last && nearestWeekday(t, target, last) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// dowExtensionMatches returns true if the day of t is one of the schedule's
+// days of week that depend on the month: the last and nth weekdays.
+func (s *SpecSchedule) dowExtensionMatches(t time.Time) bool {
+	if s.DowLast == 0 && s.DowNth == 0 {
+		return false
+	}
+	day, weekday := t.Day(), uint(t.Weekday())
+	if 1<<weekday&s.DowLast > 0 && day+7 > daysIn(t) {
+		return true
+	}
+	return 1<<(7*uint((day-1)/7)+weekday)&s.DowNth > 0
+}
+
+// daysIn returns the number of days in the month of t.
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the day of the weekday (Monday to Friday) nearest to
+// the given day, in the month of t with the given last day. The nearest weekday
+// is never in another month.
+func nearestWeekday(t time.Time, day, last int) int {
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
@@ -2,6 +2,7 @@ package cron
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -70,90 +71,211 @@ func TestActivation(t *testing.T) {
 	}
 }
 
-func TestNext(t *testing.T) {
-	runs := []struct {
+// nextTests are the activations after given times, shared by TestNext and
+// TestPrev.
+var nextTests = []struct {
+	time, spec string
+	expected   string
+}{
+	// Simple cases
+	{"Mon Jul 9 14:45 2012", "0 0/15 * * *", "Mon Jul 9 15:00 2012"},
+	{"Mon Jul 9 14:59 2012", "0 0/15 * * *", "Mon Jul 9 15:00 2012"},
+	{"Mon Jul 9 14:59:59 2012", "0 0/15 * * *", "Mon Jul 9 15:00 2012"},
+
+	// Wrap around hours
+	{"Mon Jul 9 15:45 2012", "0 20-35/15 * * *", "Mon Jul 9 16:20 2012"},
+
+	// Wrap around days
+	{"Mon Jul 9 23:46 2012", "0 */15 * * *", "Tue Jul 10 00:00 2012"},
+	{"Mon Jul 9 23:45 2012", "0 20-35/15 * * *", "Tue Jul 10 00:20 2012"},
+	{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 * * *", "Tue Jul 10 00:20:15 2012"},
+	{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 1/2 * *", "Tue Jul 10 01:20:15 2012"},
+	{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 10-12 * *", "Tue Jul 10 10:20:15 2012"},
+
+	{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 1/2 */2 * *", "Thu Jul 11 01:20:15 2012"},
+	{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 * 9-20 * *", "Wed Jul 10 00:20:15 2012"},
+	{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 * 9-20 Jul *", "Wed Jul 10 00:20:15 2012"},
+
+	// Wrap around months
+	{"Mon Jul 9 23:35 2012", "0 0 0 9 Apr-Oct ?", "Thu Aug 9 00:00 2012"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 */5 Apr,Aug,Oct Mon", "Mon Aug 6 00:00 2012"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 */5 Oct Mon", "Mon Oct 1 00:00 2012"},
+
+	// Wrap around years
+	{"Mon Jul 9 23:35 2012", "0 0 0 * Feb Mon", "Mon Feb 4 00:00 2013"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 * Feb Mon/2", "Fri Feb 1 00:00 2013"},
+
+	// Wrap around minute, hour, day, month, and year
+	{"Mon Dec 31 23:59:45 2012", "0 * * * * *", "Tue Jan 1 00:00:00 2013"},
+
+	// Leap year
+	{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ?", "Mon Feb 29 00:00 2016"},
+
+	// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
+	// (runs right after the skipped hour)
+	{"2012-03-11T00:00:00-0500", "0 30 2 11 Mar ?", "2012-03-11T03:00:00-0400"},
+
+	// hourly job
+	{"2012-03-11T00:00:00-0500", "0 0 * * * ?", "2012-03-11T01:00:00-0500"},
+	{"2012-03-11T01:00:00-0500", "0 0 * * * ?", "2012-03-11T03:00:00-0400"},
+	{"2012-03-11T03:00:00-0400", "0 0 * * * ?", "2012-03-11T04:00:00-0400"},
+	{"2012-03-11T04:00:00-0400", "0 0 * * * ?", "2012-03-11T05:00:00-0400"},
+
+	// 1am nightly job
+	{"2012-03-11T00:00:00-0500", "0 0 1 * * ?", "2012-03-11T01:00:00-0500"},
+	{"2012-03-11T01:00:00-0500", "0 0 1 * * ?", "2012-03-12T01:00:00-0400"},
+
+	// 2am nightly job (runs at 3am)
+	{"2012-03-11T00:00:00-0500", "0 0 2 * * ?", "2012-03-11T03:00:00-0400"},
+	{"2012-03-11T03:00:00-0400", "0 0 2 * * ?", "2012-03-12T02:00:00-0400"},
+
+	// Daylight savings time 2am EDT (-4) => 1am EST (-5)
+	{"2012-11-04T00:00:00-0400", "0 30 2 04 Nov ?", "2012-11-04T02:30:00-0500"},
+	// (runs once, during the first 1am hour)
+	{"2012-11-04T00:00:00-0400", "0 30 1 04 Nov ?", "2012-11-04T01:30:00-0400"},
+	{"2012-11-04T01:45:00-0400", "0 30 1 04 Nov ?", "2013-11-04T01:30:00-0500"},
+
+	// hourly job
+	{"2012-11-04T00:00:00-0400", "0 0 * * * ?", "2012-11-04T01:00:00-0400"},
+	{"2012-11-04T01:00:00-0400", "0 0 * * * ?", "2012-11-04T01:00:00-0500"},
+	{"2012-11-04T01:00:00-0500", "0 0 * * * ?", "2012-11-04T02:00:00-0500"},
+
+	// 1am nightly job (runs once)
+	{"2012-11-04T00:00:00-0400", "0 0 1 * * ?", "2012-11-04T01:00:00-0400"},
+	{"2012-11-04T01:00:00-0400", "0 0 1 * * ?", "2012-11-05T01:00:00-0500"},
+	{"2012-11-04T01:00:00-0500", "0 0 1 * * ?", "2012-11-05T01:00:00-0500"},
+
+	// 2am nightly job
+	{"2012-11-04T00:00:00-0400", "0 0 2 * * ?", "2012-11-04T02:00:00-0500"},
+	{"2012-11-04T02:00:00-0500", "0 0 2 * * ?", "2012-11-05T02:00:00-0500"},
+
+	// 3am nightly job
+	{"2012-11-04T00:00:00-0400", "0 0 3 * * ?", "2012-11-04T03:00:00-0500"},
+	{"2012-11-04T03:00:00-0500", "0 0 3 * * ?", "2012-11-05T03:00:00-0500"},
+
+	// Last days of the month
+	{"Mon Jul 9 23:35 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+	{"Tue Jul 31 00:00 2012", "0 0 0 L * ?", "Fri Aug 31 00:00 2012"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 L-2 * ?", "Sun Jul 29 00:00 2012"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 L Feb ?", "Thu Feb 28 00:00 2013"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 1,L * ?", "Tue Jul 31 00:00 2012"},
+	{"Tue Jul 31 00:00 2012", "0 0 0 1,L * ?", "Wed Aug 1 00:00 2012"},
+
+	// Nearest weekdays, within the month
+	{"Mon Jul 9 23:35 2012", "0 0 0 15W * ?", "Mon Jul 16 00:00 2012"},
+	{"Mon Jul 16 00:00 2012", "0 0 0 1W * ?", "Wed Aug 1 00:00 2012"},
+	{"Wed Aug 1 00:00 2012", "0 0 0 1W * ?", "Mon Sep 3 00:00 2012"},
+	{"Sat Sep 1 00:00 2012", "0 0 0 30W * ?", "Fri Sep 28 00:00 2012"},
+	{"Fri Aug 31 00:00 2012", "0 0 0 LW * ?", "Fri Sep 28 00:00 2012"},
+
+	// Last and nth days of week of the month
+	{"Mon Jul 9 23:35 2012", "0 0 0 ? * 5L", "Fri Jul 27 00:00 2012"},
+	{"Fri Jul 27 00:00 2012", "0 0 0 ? * FRIL", "Fri Aug 31 00:00 2012"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 ? * MON#2", "Mon Aug 13 00:00 2012"},
+	{"Mon Jul 9 23:35 2012", "0 0 0 ? * 1#5", "Mon Jul 30 00:00 2012"},
+
+	// Rare days, far apart
+	{"Mon Jul 9 23:35 2012", "0 0 0 ? Feb MON#5", "Mon Feb 29 00:00 2016"},
+	{"Mon Feb 29 00:00 2016", "0 0 0 ? Feb MON#5", "Mon Feb 29 00:00 2044"},
+	{"Mon Feb 29 00:00 2016", "0 0 0 29 Feb ?", "Sat Feb 29 00:00 2020"},
+}
+
+func TestMatches(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year | Descriptor)
+	tests := []struct {
 		time, spec string
-		expected   string
+		expected   bool
 	}{
-		// Simple cases
-		{"Mon Jul 9 14:45 2012", "0 0/15 * * *", "Mon Jul 9 15:00 2012"},
-		{"Mon Jul 9 14:59 2012", "0 0/15 * * *", "Mon Jul 9 15:00 2012"},
-		{"Mon Jul 9 14:59:59 2012", "0 0/15 * * *", "Mon Jul 9 15:00 2012"},
-
-		// Wrap around hours
-		{"Mon Jul 9 15:45 2012", "0 20-35/15 * * *", "Mon Jul 9 16:20 2012"},
-
-		// Wrap around days
-		{"Mon Jul 9 23:46 2012", "0 */15 * * *", "Tue Jul 10 00:00 2012"},
-		{"Mon Jul 9 23:45 2012", "0 20-35/15 * * *", "Tue Jul 10 00:20 2012"},
-		{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 * * *", "Tue Jul 10 00:20:15 2012"},
-		{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 1/2 * *", "Tue Jul 10 01:20:15 2012"},
-		{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 10-12 * *", "Tue Jul 10 10:20:15 2012"},
-
-		{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 1/2 */2 * *", "Thu Jul 11 01:20:15 2012"},
-		{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 * 9-20 * *", "Wed Jul 10 00:20:15 2012"},
-		{"Mon Jul 9 23:35:51 2012", "15/35 20-35/15 * 9-20 Jul *", "Wed Jul 10 00:20:15 2012"},
-
-		// Wrap around months
-		{"Mon Jul 9 23:35 2012", "0 0 0 9 Apr-Oct ?", "Thu Aug 9 00:00 2012"},
-		{"Mon Jul 9 23:35 2012", "0 0 0 */5 Apr,Aug,Oct Mon", "Mon Aug 6 00:00 2012"},
-		{"Mon Jul 9 23:35 2012", "0 0 0 */5 Oct Mon", "Mon Oct 1 00:00 2012"},
-
-		// Wrap around years
-		{"Mon Jul 9 23:35 2012", "0 0 0 * Feb Mon", "Mon Feb 4 00:00 2013"},
-		{"Mon Jul 9 23:35 2012", "0 0 0 * Feb Mon/2", "Fri Feb 1 00:00 2013"},
-
-		// Wrap around minute, hour, day, month, and year
-		{"Mon Dec 31 23:59:45 2012", "0 * * * * *", "Tue Jan 1 00:00:00 2013"},
-
-		// Leap year
-		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ?", "Mon Feb 29 00:00 2016"},
-
-		// Daylight savings time 2am EST (-5) -> 3am EDT (-4)
-		{"2012-03-11T00:00:00-0500", "0 30 2 11 Mar ?", "2013-03-11T02:30:00-0400"},
-
-		// hourly job
-		{"2012-03-11T00:00:00-0500", "0 0 * * * ?", "2012-03-11T01:00:00-0500"},
-		{"2012-03-11T01:00:00-0500", "0 0 * * * ?", "2012-03-11T03:00:00-0400"},
-		{"2012-03-11T03:00:00-0400", "0 0 * * * ?", "2012-03-11T04:00:00-0400"},
-		{"2012-03-11T04:00:00-0400", "0 0 * * * ?", "2012-03-11T05:00:00-0400"},
-
-		// 1am nightly job
-		{"2012-03-11T00:00:00-0500", "0 0 1 * * ?", "2012-03-11T01:00:00-0500"},
-		{"2012-03-11T01:00:00-0500", "0 0 1 * * ?", "2012-03-12T01:00:00-0400"},
-
-		// 2am nightly job (skipped)
-		{"2012-03-11T00:00:00-0500", "0 0 2 * * ?", "2012-03-12T02:00:00-0400"},
-
-		// Daylight savings time 2am EDT (-4) => 1am EST (-5)
-		{"2012-11-04T00:00:00-0400", "0 30 2 04 Nov ?", "2012-11-04T02:30:00-0500"},
-		{"2012-11-04T01:45:00-0400", "0 30 1 04 Nov ?", "2012-11-04T01:30:00-0500"},
+		// Every field
+		{"Mon Jul 9 15:00 2012", "0 0/15 * * * *", true},
+		{"Mon Jul 9 15:40 2012", "0 0/15 * * * *", false},
+		{"Mon Jul 9 15:00:30 2012", "0 0/15 * * * *", false},
+		{"Mon Jul 9 15:00:30 2012", "30 0/15 * * * *", true},
+		{"Mon Jul 9 08:30 2012", "0 30 9 * * *", false},
+		{"Mon Jul 9 08:30 2012", "0 30 8 * Jun *", false},
+		{"Mon Jul 9 08:30 2012", "0 30 8 * Jul *", true},
+
+		// Fractions of a second
+		{"Mon Jul 9 15:00:00.5 2012", "* * * * * *", false},
+
+		// Days of month and of week
+		{"Sun Jul 15 00:00 2012", "0 0 0 1,15 * Mon", true},
+		{"Mon Jul 9 00:00 2012", "0 0 0 1,15 * Mon", true},
+		{"Tue Jul 10 00:00 2012", "0 0 0 1,15 * Mon", false},
+		{"Sun Jul 15 00:00 2012", "0 0 0 * * Mon", false},
+		{"Sun Jul 15 00:00 2012", "0 0 0 15 * *", true},
+		{"Tue Jul 31 00:00 2012", "0 0 0 L * ?", true},
+		{"Mon Jul 30 00:00 2012", "0 0 0 L * ?", false},
+		{"Mon Jul 16 00:00 2012", "0 0 0 15W * ?", true},
+		{"Mon Jul 30 00:00 2012", "0 0 0 ? * MON#5", true},
+		{"Fri Jul 27 00:00 2012", "0 0 0 ? * 5L", true},
+
+		// Years and descriptors
+		{"Mon Jul 9 00:00 2012", "0 0 0 * * ? 2012", true},
+		{"Mon Jul 9 00:00 2012", "0 0 0 * * ? 2013", false},
+		{"Sun Jul 1 00:00 2012", "@monthly", true},
+		{"Mon Jul 2 00:00 2012", "@monthly", false},
 
-		// hourly job
-		{"2012-11-04T00:00:00-0400", "0 0 * * * ?", "2012-11-04T01:00:00-0400"},
-		{"2012-11-04T01:00:00-0400", "0 0 * * * ?", "2012-11-04T01:00:00-0500"},
-		{"2012-11-04T01:00:00-0500", "0 0 * * * ?", "2012-11-04T02:00:00-0500"},
+		// Time zones
+		{"2012-07-09T09:30:00-0400", "0 30 9 * * *", true},
+		{"2012-07-09T09:30:00-0400", "CRON_TZ=Europe/London 0 30 14 * * *", true},
+		{"2012-07-09T09:30:00-0400", "CRON_TZ=Europe/London 0 30 9 * * *", false},
 
-		// 1am nightly job (runs twice)
-		{"2012-11-04T00:00:00-0400", "0 0 1 * * ?", "2012-11-04T01:00:00-0400"},
-		{"2012-11-04T01:00:00-0400", "0 0 1 * * ?", "2012-11-04T01:00:00-0500"},
-		{"2012-11-04T01:00:00-0500", "0 0 1 * * ?", "2012-11-05T01:00:00-0500"},
+		// Wall clock times repeated when the clocks go back match twice
+		{"2012-11-04T01:30:00-0400", "0 30 1 * * *", true},
+		{"2012-11-04T01:30:00-0500", "0 30 1 * * *", true},
+	}
 
-		// 2am nightly job
-		{"2012-11-04T00:00:00-0400", "0 0 2 * * ?", "2012-11-04T02:00:00-0500"},
-		{"2012-11-04T02:00:00-0500", "0 0 2 * * ?", "2012-11-05T02:00:00-0500"},
+	for _, c := range tests {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if actual := sched.(*SpecSchedule).Matches(getTime(c.time)); actual != c.expected {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, c.expected, actual)
+		}
+	}
+}
 
-		// 3am nightly job
-		{"2012-11-04T00:00:00-0400", "0 0 3 * * ?", "2012-11-04T03:00:00-0500"},
-		{"2012-11-04T03:00:00-0500", "0 0 3 * * ?", "2012-11-05T03:00:00-0500"},
+func TestNext(t *testing.T) {
+	for _, c := range nextTests {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
 
-		// Unsatisfiable
-		{"Mon Jul 9 23:35 2012", "0 0 0 30 Feb ?", ""},
-		{"Mon Jul 9 23:35 2012", "0 0 0 31 Apr ?", ""},
+func TestNextYear(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Jump straight to the next year, however far
+		{"Mon Jul 9 23:35 2012", "0 0 12 * * ? 2027", "Fri Jan 1 12:00 2027"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 1 Jan ? 2099", "Thu Jan 1 00:00 2099"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 9 Jul ? 2012/12", "Tue Jul 9 00:00 2024"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ? 2030-2040", "Sun Feb 29 00:00 2032"},
+
+		// Any year
+		{"Mon Jul 9 23:35 2012", "0 0 12 * * ? *", "Tue Jul 10 12:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 12 * * ?", "Tue Jul 10 12:00 2012"},
+
+		// No more years
+		{"Fri Jan 1 12:00 2027", "0 0 12 1 1 ? 2027", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 * * ? 1970-2011", ""},
 	}
 
 	for _, c := range runs {
-		sched, err := Parse(c.spec)
+		sched, err := parser.Parse(c.spec)
 		if err != nil {
 			t.Error(err)
 			continue
@@ -166,12 +288,74 @@ func TestNext(t *testing.T) {
 	}
 }
 
+func TestNextWithin(t *testing.T) {
+	sched, _ := Parse("0 0 0 ? Feb MON#5")
+	from := getTime("Mon Feb 29 00:00 2016")
+	if actual := sched.(*SpecSchedule).NextWithin(from, 27); !actual.IsZero() {
+		t.Errorf("expected no time within 27 years, got %v", actual)
+	}
+	if actual, expected := sched.(*SpecSchedule).NextWithin(from, 28), getTime("Mon Feb 29 00:00 2044"); !actual.Equal(expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, actual)
+	}
+}
+
+func TestSatisfiable(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+	for _, spec := range []string{
+		"* * * * * ?",
+		"0 0 0 29 Feb ?",
+		"0 0 0 ? Feb MON#5",
+		"0 0 0 31 Jan-Mar ?",
+		"0 0 0 LW Feb ?",
+		"0 0 0 29 Feb ? 2028",
+	} {
+		sched, err := parser.Parse(spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !sched.(*SpecSchedule).Satisfiable() {
+			t.Errorf("%s: expected satisfiable", spec)
+		}
+	}
+
+	february30 := &SpecSchedule{Second: 1, Minute: 1, Hour: 1, Dom: 1 << 30, Month: 1 << 2, Dow: all(dow)}
+	if february30.Satisfiable() {
+		t.Error("expected February 30th unsatisfiable")
+	}
+}
+
+// Test that the parser rejects specs that never activate.
+func TestParseUnsatisfiable(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+	tests := []struct {
+		spec, err string
+	}{
+		{"0 0 0 30 Feb ?", "Day of month 30 never occurs in the given months"},
+		{"0 0 0 30,31 Feb ?", "Day of month 30 never occurs in the given months"},
+		{"0 0 0 31 Apr,Jun,Sep,Nov ?", "Day of month 31 never occurs in the given months"},
+		{"0 0 0 L-29 Feb ?", "Days never occur in the given months"},
+		{"0 0 0 30W Feb ?", "Days never occur in the given months"},
+		{"0 0 0 29 Feb ? 2025-2027", "Days never occur in the given years"},
+		{"0 0 0 ? Feb MON#5 2017-2043", "Days never occur in the given years"},
+	}
+
+	for _, c := range tests {
+		_, err := parser.Parse(c.spec)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s => expected %v, got %v", c.spec, c.err, err)
+		}
+	}
+}
+
 func TestErrors(t *testing.T) {
 	invalidSpecs := []string{
 		"xyz",
 		"60 0 * * *",
 		"0 60 * * *",
 		"0 0 * * XYZ",
+		"0 0 0 30 Feb ?",
+		"0 0 0 31 Apr ?",
 	}
 	for _, spec := range invalidSpecs {
 		_, err := Parse(spec)
@@ -231,6 +415,216 @@ func TestNextWithTz(t *testing.T) {
 	}
 }
 
+// Daylight saving time transitions follow Vixie cron: wall clock times skipped
+// by the clocks going forward run right after the gap, and wall clock times
+// repeated by the clocks going back run once, unless the hour is a wildcard.
+var nextDSTTests = []struct {
+	zone, time, spec string
+	expected         string
+}{
+	// New York: 2am EST (-5) -> 3am EDT (-4)
+	{"America/New_York", "2019-03-10T00:00:00-0500", "0 30 2 * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T00:00:00-0500", "0 0 2 * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T03:00:00-0400", "0 30 2 * * *", "2019-03-11T02:30:00-0400"},
+	{"America/New_York", "2019-03-10T00:00:00-0500", "0 0,30 2 * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T03:00:00-0400", "0 0,30 2 * * *", "2019-03-11T02:00:00-0400"},
+	{"America/New_York", "2019-03-10T00:00:00-0500", "0 0 3 * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T01:00:00-0500", "0 0 * * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T01:45:00-0500", "0 */15 * * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T03:00:00-0400", "0 */15 * * * *", "2019-03-10T03:15:00-0400"},
+	{"America/New_York", "2019-03-10T01:59:59-0500", "* * * * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T01:00:00-0500", "0 0 1-3 * * *", "2019-03-10T03:00:00-0400"},
+	{"America/New_York", "2019-03-10T03:00:00-0400", "0 0 1-3 * * *", "2019-03-11T01:00:00-0400"},
+	// (unless the hour is a wildcard)
+	{"America/New_York", "2019-03-10T01:30:00-0500", "0 30 * * * *", "2019-03-10T03:30:00-0400"},
+	{"America/New_York", "2019-03-10T01:30:00-0500", "0 30 */2 * * *", "2019-03-10T04:30:00-0400"},
+
+	// New York: 2am EDT (-4) -> 1am EST (-5)
+	{"America/New_York", "2019-11-03T00:00:00-0400", "0 30 1 * * *", "2019-11-03T01:30:00-0400"},
+	{"America/New_York", "2019-11-03T01:30:00-0400", "0 30 1 * * *", "2019-11-04T01:30:00-0500"},
+	{"America/New_York", "2019-11-03T01:00:00-0500", "0 30 1 * * *", "2019-11-04T01:30:00-0500"},
+	{"America/New_York", "2019-11-03T01:40:00-0400", "0 */20 1 * * *", "2019-11-04T01:00:00-0500"},
+	{"America/New_York", "2019-11-03T00:00:00-0400", "0 0 2 * * *", "2019-11-03T02:00:00-0500"},
+	{"America/New_York", "2019-11-03T01:30:00-0400", "0 0 * * * *", "2019-11-03T01:00:00-0500"},
+	{"America/New_York", "2019-11-03T01:00:00-0500", "0 0 * * * *", "2019-11-03T02:00:00-0500"},
+	{"America/New_York", "2019-11-03T01:30:00-0400", "0 30 * * * *", "2019-11-03T01:30:00-0500"},
+	{"America/New_York", "2019-11-03T01:40:00-0400", "0 */20 * * * *", "2019-11-03T01:00:00-0500"},
+
+	// London: 1am GMT (+0) -> 2am BST (+1)
+	{"Europe/London", "2019-03-31T00:00:00+0000", "0 30 1 * * *", "2019-03-31T02:00:00+0100"},
+	{"Europe/London", "2019-03-31T00:30:00+0000", "0 0 * * * *", "2019-03-31T02:00:00+0100"},
+
+	// London: 2am BST (+1) -> 1am GMT (+0)
+	{"Europe/London", "2019-10-27T00:00:00+0100", "0 30 1 * * *", "2019-10-27T01:30:00+0100"},
+	{"Europe/London", "2019-10-27T01:30:00+0100", "0 30 1 * * *", "2019-10-28T01:30:00+0000"},
+	{"Europe/London", "2019-10-27T01:30:00+0100", "0 30 * * * *", "2019-10-27T01:30:00+0000"},
+
+	// Sydney: 3am AEDT (+11) -> 2am AEST (+10)
+	{"Australia/Sydney", "2019-04-07T01:00:00+1100", "0 30 2 * * *", "2019-04-07T02:30:00+1100"},
+	{"Australia/Sydney", "2019-04-07T02:30:00+1100", "0 30 2 * * *", "2019-04-08T02:30:00+1000"},
+	{"Australia/Sydney", "2019-04-07T02:30:00+1100", "0 30 * * * *", "2019-04-07T02:30:00+1000"},
+
+	// Sydney: 2am AEST (+10) -> 3am AEDT (+11)
+	{"Australia/Sydney", "2019-10-06T00:00:00+1000", "0 30 2 * * *", "2019-10-06T03:00:00+1100"},
+	{"Australia/Sydney", "2019-10-06T01:00:00+1000", "0 0 * * * *", "2019-10-06T03:00:00+1100"},
+
+	// Lord Howe: 2am (+11) -> 1:30am (+10:30)
+	{"Australia/Lord_Howe", "2019-04-07T01:00:00+1100", "0 45 1 * * *", "2019-04-07T01:45:00+1100"},
+	{"Australia/Lord_Howe", "2019-04-07T01:45:00+1100", "0 45 1 * * *", "2019-04-08T01:45:00+1030"},
+	{"Australia/Lord_Howe", "2019-04-07T01:45:00+1100", "0 45 * * * *", "2019-04-07T01:45:00+1030"},
+	{"Australia/Lord_Howe", "2019-04-07T01:45:00+1100", "0 0 * * * *", "2019-04-07T02:00:00+1030"},
+
+	// Lord Howe: 2am (+10:30) -> 2:30am (+11)
+	{"Australia/Lord_Howe", "2019-10-06T01:00:00+1030", "0 15 2 * * *", "2019-10-06T02:30:00+1100"},
+	{"Australia/Lord_Howe", "2019-10-06T01:00:00+1030", "0 45 2 * * *", "2019-10-06T02:45:00+1100"},
+	{"Australia/Lord_Howe", "2019-10-06T01:00:00+1030", "0 0 * * * *", "2019-10-06T03:00:00+1100"},
+	{"Australia/Lord_Howe", "2019-10-06T02:30:00+1100", "0 0 * * * *", "2019-10-06T03:00:00+1100"},
+
+	// Santiago: midnight (-3) -> 11pm (-4) the day before
+	{"America/Santiago", "2019-04-06T22:00:00-0300", "0 30 23 * * *", "2019-04-06T23:30:00-0300"},
+	{"America/Santiago", "2019-04-06T23:30:00-0300", "0 30 23 * * *", "2019-04-07T23:30:00-0400"},
+	{"America/Santiago", "2019-04-06T23:30:00-0300", "0 0 0 * * *", "2019-04-07T00:00:00-0400"},
+
+	// Santiago: midnight (-4) -> 1am (-3), so the day starts at 1am
+	{"America/Santiago", "2019-09-07T12:00:00-0400", "0 0 0 * * *", "2019-09-08T01:00:00-0300"},
+	{"America/Santiago", "2019-09-07T12:00:00-0400", "@daily", "2019-09-08T01:00:00-0300"},
+	{"America/Santiago", "2019-09-07T12:00:00-0400", "0 0 0 8 Sep ?", "2019-09-08T01:00:00-0300"},
+	{"America/Santiago", "2019-09-08T01:00:00-0300", "0 0 0 * * *", "2019-09-09T00:00:00-0300"},
+}
+
+func TestNextDST(t *testing.T) {
+	for _, c := range nextDSTTests {
+		loc, err := time.LoadLocation(c.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTimeTZ(c.time).In(loc))
+		expected := getTimeTZ(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s %s, \"%s\": (expected) %v != %v (actual)", c.zone, c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+// TestPrev walks the activations of the TestNext and TestNextDST tables
+// backwards: the activation just before each expected time is at or before the
+// time searched from, and its next activation is the expected time.
+func TestPrev(t *testing.T) {
+	check := func(name, spec string, from, expected time.Time) {
+		sched, err := Parse(spec)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if actual := sched.(*SpecSchedule).Prev(expected.Add(time.Second)); !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", name, spec, expected, actual)
+		}
+		prev := sched.(*SpecSchedule).Prev(expected)
+		if prev.After(from) || !sched.Next(prev).Equal(expected) {
+			t.Errorf("%s, \"%s\": %v is not the activation before %v", name, spec, prev, expected)
+		}
+	}
+
+	for _, c := range nextTests {
+		check(c.time, c.spec, getTime(c.time), getTime(c.expected))
+	}
+	for _, c := range nextDSTTests {
+		loc, err := time.LoadLocation(c.zone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(c.zone+" "+c.time, c.spec, getTimeTZ(c.time), getTimeTZ(c.expected).In(loc))
+	}
+}
+
+func TestPrevEdges(t *testing.T) {
+	parser := NewParser(Second | Minute | Hour | Dom | Month | Dow | Year)
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Activations at the given time are not before it
+		{"Mon Jul 9 15:00 2012", "0 0 * * * ?", "Mon Jul 9 14:00 2012"},
+		{"Mon Jul 9 15:00:00.5 2012", "0 0 * * * ?", "Mon Jul 9 15:00 2012"},
+
+		// Wrap around days, months and years
+		{"Tue Jul 10 00:00 2012", "0 30 23 * * ?", "Mon Jul 9 23:30 2012"},
+		{"Wed Aug 1 00:00 2012", "0 0 0 L * ?", "Tue Jul 31 00:00 2012"},
+		{"Tue Jan 1 00:00 2013", "0 0 12 * * ?", "Mon Dec 31 12:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 29 Feb ?", "Wed Feb 29 00:00 2012"},
+		{"Mon Jul 9 23:35 2012", "0 0 0 ? Feb MON#5", "Mon Feb 29 00:00 1988"},
+
+		// Years
+		{"Mon Jul 9 23:35 2012", "0 0 0 10 Jul ? 2000/12", "Mon Jul 10 00:00 2000"},
+		{"Mon Jul 9 23:35 2012", "0 0 12 * * ? 2027", ""},
+		{"Mon Jul 9 23:35 2012", "0 0 0 * * ? 1970-2011", "Sat Dec 31 00:00 2011"},
+	}
+
+	for _, c := range runs {
+		sched, err := parser.Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.(*SpecSchedule).Prev(getTime(c.time))
+		expected := getTime(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+	}
+}
+
+func TestNextWithCronTZ(t *testing.T) {
+	runs := []struct {
+		time, spec string
+		expected   string
+	}{
+		// Daily job in New York, before and after 2am EST (-5) -> 3am EDT (-4)
+		{"2012-03-09T15:00:00+0000", "CRON_TZ=America/New_York 0 0 9 * * *", "2012-03-10T14:00:00+0000"},
+		{"2012-03-10T15:00:00+0000", "CRON_TZ=America/New_York 0 0 9 * * *", "2012-03-11T13:00:00+0000"},
+		{"2012-03-11T13:00:00+0000", "CRON_TZ=America/New_York 0 0 9 * * *", "2012-03-12T13:00:00+0000"},
+
+		// Daily job in New York, across 2am EDT (-4) => 1am EST (-5)
+		{"2012-11-03T14:00:00+0000", "TZ=America/New_York 0 0 9 * * *", "2012-11-04T14:00:00+0000"},
+		{"2012-11-04T14:00:00+0000", "TZ=America/New_York 0 0 9 * * *", "2012-11-05T14:00:00+0000"},
+
+		// Hourly job in New York, evaluated from a UTC time
+		{"2012-03-11T06:00:00+0000", "CRON_TZ=America/New_York 0 0 * * * ?", "2012-03-11T07:00:00+0000"},
+		{"2012-11-04T05:00:00+0000", "CRON_TZ=America/New_York 0 0 * * * ?", "2012-11-04T06:00:00+0000"},
+
+		// London moves to BST (+1) at 1am GMT
+		{"2012-03-24T12:00:00+0000", "CRON_TZ=Europe/London 0 30 8 * * *", "2012-03-25T07:30:00+0000"},
+		{"2012-10-27T12:00:00+0000", "CRON_TZ=Europe/London 0 30 8 * * *", "2012-10-28T08:30:00+0000"},
+
+		// The result is reported in the zone of the given time
+		{"2016-01-03T13:09:03+0530", "CRON_TZ=Asia/Tokyo 0 0 9 * * *", "2016-01-04T05:30:00+0530"},
+
+		// Descriptors
+		{"2016-01-03T14:09:03+0000", "CRON_TZ=Asia/Tokyo @daily", "2016-01-03T15:00:00+0000"},
+		{"2016-01-03T14:09:03+0000", "TZ=Asia/Tokyo @monthly", "2016-01-31T15:00:00+0000"},
+	}
+	for _, c := range runs {
+		sched, err := Parse(c.spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		actual := sched.Next(getTimeTZ(c.time))
+		expected := getTimeTZ(c.expected)
+		if !actual.Equal(expected) {
+			t.Errorf("%s, \"%s\": (expected) %v != %v (actual)", c.time, c.spec, expected, actual)
+		}
+		if actual.Format("-0700") != getTimeTZ(c.time).Format("-0700") {
+			t.Errorf("%s, \"%s\": expected result in the zone of the given time, got %v", c.time, c.spec, actual)
+		}
+	}
+}
+
 func getTimeTZ(value string) time.Time {
 	if value == "" {
 		return time.Time{}
@@ -249,3 +643,29 @@ func getTimeTZ(value string) time.Time {
 
 	return t
 }
+
+func BenchmarkNext(b *testing.B) {
+	benchmarks := []struct {
+		name, spec string
+	}{
+		{"EverySecond", "* * * * * *"},
+		{"Hourly", "0 0 * * * *"},
+		{"Weekdays", "0 30 9 * * MON-FRI"},
+		{"LastDay", "0 0 0 L * ?"},
+		{"Leap", "0 0 0 29 Feb ?"},
+		{"LeapMonday", "0 0 0 ? Feb MON#5"},
+	}
+	from := time.Date(2012, time.July, 9, 23, 35, 0, 0, time.UTC)
+	for _, bm := range benchmarks {
+		sched, err := Parse(bm.spec)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sched.Next(from)
+			}
+		})
+	}
+}
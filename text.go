@@ -0,0 +1,154 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+)
+
+// textParser parses the text of schedules: it accepts what Parse does, and the
+// optional year field that SpecSchedule.String may give.
+var textParser = NewParser(
+	Second | Minute | Hour | Dom | Month | DowOptional | Year | Descriptor,
+)
+
+// MarshalText implements encoding.TextMarshaler, giving the canonical spec of
+// the schedule.
+func (s *SpecSchedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing a spec as Parse
+// does, with an optional year field. The spec must give a SpecSchedule, rather
+// than an interval or a one-off time.
+func (s *SpecSchedule) UnmarshalText(text []byte) error {
+	schedule, err := textParser.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	spec, ok := schedule.(*SpecSchedule)
+	if !ok {
+		return fmt.Errorf("Expected a cron spec, found %T: %s", schedule, text)
+	}
+	*s = *spec
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, giving the schedule as
+// "@every <duration>", followed by " from <time>" if it is anchored.
+func (schedule ConstantDelaySchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing an "@every"
+// descriptor.
+func (schedule *ConstantDelaySchedule) UnmarshalText(text []byte) error {
+	parsed, err := textParser.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	delay, ok := parsed.(ConstantDelaySchedule)
+	if !ok {
+		return fmt.Errorf("Expected an @every descriptor, found %T: %s", parsed, text)
+	}
+	*schedule = delay
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, giving the schedule as
+// "@at <time>".
+func (schedule OnceSchedule) MarshalText() ([]byte, error) {
+	return []byte(schedule.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing an "@at"
+// descriptor.
+func (schedule *OnceSchedule) UnmarshalText(text []byte) error {
+	parsed, err := textParser.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	once, ok := parsed.(OnceSchedule)
+	if !ok {
+		return fmt.Errorf("Expected an @at descriptor, found %T: %s", parsed, text)
+	}
+	*schedule = once
+	return nil
+}
+
+// Spec holds any schedule given by a spec, together with the spec as it was
+// written. It may be used as the type of a field in a configuration, so that
+// the spec is parsed, and checked, when the configuration is decoded from JSON,
+// YAML or any other format that supports encoding.TextUnmarshaler:
+//
+//	var config struct {
+//		Schedule cron.Spec `json:"schedule"`
+//	}
+//	err := json.Unmarshal([]byte(`{"schedule": "0 30 9 * * 1-5"}`), &config)
+//	c.Schedule(config.Schedule, job)
+//
+// Entries scheduled with a Spec keep its text, as their Spec. The zero Spec has
+// no schedule, and must not be scheduled.
+type Spec struct {
+	Text string
+	Schedule
+}
+
+// Prev returns the latest activation of the schedule before t, if it is a
+// PrevScheduler, or the zero time.
+func (s Spec) Prev(t time.Time) time.Time {
+	if p, ok := s.Schedule.(PrevScheduler); ok {
+		return p.Prev(t)
+	}
+	return time.Time{}
+}
+
+// Matches reports whether the schedule activates at t. Schedules that are not
+// Matchers are asked for their next activation from just before t.
+func (s Spec) Matches(t time.Time) bool {
+	if m, ok := s.Schedule.(Matcher); ok {
+		return m.Matches(t)
+	}
+	return s.Schedule.Next(t.Add(-time.Nanosecond)).Equal(t)
+}
+
+// unwrapSpec returns the schedule held by a Spec, or the schedule itself.
+func unwrapSpec(schedule Schedule) Schedule {
+	if s, ok := schedule.(Spec); ok {
+		return s.Schedule
+	}
+	return schedule
+}
+
+// ParseSpec parses the spec as the text of a Spec.
+func ParseSpec(text string) (Spec, error) {
+	var spec Spec
+	err := spec.UnmarshalText([]byte(text))
+	return spec, err
+}
+
+// String returns the text of the spec.
+func (s Spec) String() string {
+	return s.Text
+}
+
+// MarshalText implements encoding.TextMarshaler, giving the spec as it was
+// written, or the canonical spec of its schedule if it has no text.
+func (s Spec) MarshalText() ([]byte, error) {
+	if s.Text == "" {
+		if stringer, ok := s.Schedule.(fmt.Stringer); ok {
+			return []byte(stringer.String()), nil
+		}
+	}
+	return []byte(s.Text), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing any spec that
+// Parse accepts, with an optional year field.
+func (s *Spec) UnmarshalText(text []byte) error {
+	schedule, err := textParser.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	s.Text, s.Schedule = string(text), schedule
+	return nil
+}
@@ -0,0 +1,126 @@
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpecScheduleText(t *testing.T) {
+	var s SpecSchedule
+	if err := s.UnmarshalText([]byte("0 0,15,30,45 9 * * mon-fri")); err != nil {
+		t.Fatal(err)
+	}
+	text, _ := s.MarshalText()
+	if string(text) != "0 0/15 9 * * 1-5" {
+		t.Errorf("unexpected text %q", text)
+	}
+	if err := s.UnmarshalText([]byte("@every 1h")); err == nil {
+		t.Error("expected an error unmarshaling an interval into a SpecSchedule")
+	}
+
+	var every ConstantDelaySchedule
+	anchored := EveryFrom(time.Date(2026, time.December, 1, 9, 0, 0, 0, time.FixedZone("", 3600)), time.Hour)
+	text, _ = anchored.MarshalText()
+	if string(text) != "@every 1h0m0s from 2026-12-01T09:00:00+01:00" {
+		t.Errorf("unexpected text %q", text)
+	}
+	if err := every.UnmarshalText(text); err != nil || !every.Equal(anchored) {
+		t.Errorf("unexpected %v, %v", every, err)
+	}
+	if err := every.UnmarshalText([]byte("@every 90m")); err != nil || every.Delay != 90*time.Minute {
+		t.Errorf("unexpected %v, %v", every, err)
+	}
+	if err := every.UnmarshalText([]byte("@hourly")); err == nil {
+		t.Error("expected an error unmarshaling a cron spec into a ConstantDelaySchedule")
+	}
+
+	var once OnceSchedule
+	if err := once.UnmarshalText([]byte("@at 2026-12-01T09:00:00Z")); err != nil ||
+		!once.Time.Equal(time.Date(2026, time.December, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected %v, %v", once, err)
+	}
+}
+
+func TestScheduleJSON(t *testing.T) {
+	type config struct {
+		Spec    Spec                  `json:"spec"`
+		Cron    *SpecSchedule         `json:"cron"`
+		Every   ConstantDelaySchedule `json:"every"`
+		Once    OnceSchedule          `json:"once"`
+		Yearly  Spec                  `json:"yearly"`
+		Missing *SpecSchedule         `json:"missing,omitempty"`
+	}
+	in := `{"spec":"TZ=UTC 0 30 9 * * MON-FRI","cron":"@weekly","every":"@every 1h30m0s","once":"@at 2026-12-01T09:00:00Z","yearly":"0 0 12 1 1 ? 2027"}`
+	var c config
+	if err := json.Unmarshal([]byte(in), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Spec.Text != "TZ=UTC 0 30 9 * * MON-FRI" || !Equal(c.Spec.Schedule, mustParse(t, "TZ=UTC 0 30 9 * * 1-5")) {
+		t.Errorf("unexpected spec %+v", c.Spec)
+	}
+	if !c.Cron.Equal(mustParse(t, "@weekly").(*SpecSchedule)) || c.Every.Delay != 90*time.Minute {
+		t.Errorf("unexpected schedules %+v", c)
+	}
+
+	// Specs keep their text, and other schedules give their canonical spec.
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"spec":"TZ=UTC 0 30 9 * * MON-FRI","cron":"@weekly","every":"@every 1h30m0s","once":"@at 2026-12-01T09:00:00Z","yearly":"0 0 12 1 1 ? 2027"}`
+	if string(out) != expected {
+		t.Errorf("(expected) %s != %s (actual)", expected, out)
+	}
+	var again config
+	if err := json.Unmarshal(out, &again); err != nil || !reflect.DeepEqual(again, c) {
+		t.Errorf("round trip gives %+v, %v", again, err)
+	}
+
+	// Invalid specs fail to decode, with the parse error.
+	err = json.Unmarshal([]byte(`{"spec":"0 60 * * * *"}`), &c)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Field != "minute" {
+		t.Errorf("expected a parse error in the minute field, got %v", err)
+	}
+}
+
+func TestScheduleSpec(t *testing.T) {
+	spec, err := ParseSpec("0 30 9 * * MON-FRI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron := New()
+	id, err := cron.ScheduleEntry(spec, FuncJob(func() {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := cron.entry(id); e.Spec != "0 30 9 * * MON-FRI" {
+		t.Errorf("expected the entry to keep the spec, got %q", e.Spec)
+	}
+}
+
+// Test that a Spec tells the previous and matching activations of its schedule.
+func TestSpecPrevMatches(t *testing.T) {
+	spec, err := ParseSpec("TZ=UTC 0 30 9 * * MON-FRI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, time.October, 19, 9, 30, 0, 0, time.UTC)
+	if prev := spec.Prev(at.Add(time.Hour)); !prev.Equal(at) {
+		t.Errorf("(expected) %v != %v (actual)", at, prev)
+	}
+	if !spec.Matches(at) || spec.Matches(at.Add(time.Minute)) {
+		t.Errorf("expected the spec to match %v only", at)
+	}
+
+	custom := Spec{"custom", customSchedule{}}
+	if prev := custom.Prev(at); !prev.IsZero() {
+		t.Errorf("expected no previous activation, got %v", prev)
+	}
+	if custom.Matches(at) {
+		t.Errorf("expected the custom schedule not to match %v", at)
+	}
+}
@@ -0,0 +1,51 @@
+package cron
+
+import (
+	"iter"
+	"time"
+)
+
+// Times returns the activation times of the schedule later than the given
+// time, in order, as given by Next. The sequence ends when Next returns the
+// zero time, or a time that is not later than the previous one, so that a
+// schedule that does not move forward cannot loop forever.
+func Times(schedule Schedule, from time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		for t := from; ; {
+			next := schedule.Next(t)
+			if next.IsZero() || !next.After(t) || !yield(next) {
+				return
+			}
+			t = next
+		}
+	}
+}
+
+// NextN returns the next n activation times of the schedule, later than the
+// given time. It returns fewer if the schedule ends, as for Times.
+func NextN(schedule Schedule, from time.Time, n int) []time.Time {
+	var times []time.Time
+	if n <= 0 {
+		return times
+	}
+	for t := range Times(schedule, from) {
+		times = append(times, t)
+		if len(times) == n {
+			break
+		}
+	}
+	return times
+}
+
+// Between returns the activation times of the schedule later than from and no
+// later than to, as for Times.
+func Between(schedule Schedule, from, to time.Time) []time.Time {
+	var times []time.Time
+	for t := range Times(schedule, from) {
+		if t.After(to) {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
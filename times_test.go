@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stuckSchedule activates every hour until the given time, and then keeps
+// returning it.
+type stuckSchedule struct{ until time.Time }
+
+func (s stuckSchedule) Next(t time.Time) time.Time {
+	if next := t.Add(time.Hour); next.Before(s.until) {
+		return next
+	}
+	return s.until
+}
+
+func TestNextN(t *testing.T) {
+	from := getTime("Mon Jul 9 14:45 2012")
+	tests := []struct {
+		schedule Schedule
+		n        int
+		expected []string
+	}{
+		{mustParse(t, "0 0/15 * * * *"), 3, []string{"Mon Jul 9 15:00 2012", "Mon Jul 9 15:15 2012", "Mon Jul 9 15:30 2012"}},
+		{mustParse(t, "0 0 0 29 Feb ?"), 2, []string{"Mon Feb 29 00:00 2016", "Sat Feb 29 00:00 2020"}},
+		{Every(time.Hour), 2, []string{"Mon Jul 9 15:45 2012", "Mon Jul 9 16:45 2012"}},
+		{mustParse(t, "0 0 0 * * *"), 0, nil},
+
+		// Schedules that end
+		{At(getTime("Mon Jul 9 15:00 2012")), 3, []string{"Mon Jul 9 15:00 2012"}},
+		{At(getTime("Mon Jul 9 14:00 2012")), 3, nil},
+		{stuckSchedule{getTime("Mon Jul 9 16:00 2012")}, 5, []string{"Mon Jul 9 15:45 2012", "Mon Jul 9 16:00 2012"}},
+		{stuckSchedule{getTime("Mon Jul 9 14:00 2012")}, 5, nil},
+	}
+	for i, c := range tests {
+		if actual := NextN(c.schedule, from, c.n); !reflect.DeepEqual(actual, getTimes(c.expected)) {
+			t.Errorf("%d: (expected) %v != %v (actual)", i, getTimes(c.expected), actual)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		schedule Schedule
+		from, to string
+		expected []string
+	}{
+		{mustParse(t, "0 0/15 * * * *"), "Mon Jul 9 14:45 2012", "Mon Jul 9 15:30 2012", []string{"Mon Jul 9 15:00 2012", "Mon Jul 9 15:15 2012", "Mon Jul 9 15:30 2012"}},
+		{mustParse(t, "0 0/15 * * * *"), "Mon Jul 9 14:46 2012", "Mon Jul 9 14:59 2012", nil},
+		{mustParse(t, "0 0 0 * * *"), "Mon Jul 9 14:45 2012", "Mon Jul 9 14:00 2012", nil},
+		{At(getTime("Tue Jan 1 12:00 2013")), "Mon Jul 9 14:45 2012", "Wed Jan 1 00:00 2100", []string{"Tue Jan 1 12:00 2013"}},
+		{stuckSchedule{getTime("Mon Jul 9 16:00 2012")}, "Mon Jul 9 14:45 2012", "Tue Jul 10 00:00 2012", []string{"Mon Jul 9 15:45 2012", "Mon Jul 9 16:00 2012"}},
+	}
+	for _, c := range tests {
+		actual := Between(c.schedule, getTime(c.from), getTime(c.to))
+		if !reflect.DeepEqual(actual, getTimes(c.expected)) {
+			t.Errorf("%s - %s: (expected) %v != %v (actual)", c.from, c.to, getTimes(c.expected), actual)
+		}
+	}
+}
+
+func TestTimes(t *testing.T) {
+	var times []time.Time
+	for next := range Times(mustParse(t, "0 0 9 * * mon-fri"), getTime("Fri Jul 6 12:00 2012")) {
+		if next.After(getTime("Wed Jul 11 00:00 2012")) {
+			break
+		}
+		times = append(times, next)
+	}
+	expected := getTimes([]string{"Mon Jul 9 09:00 2012", "Tue Jul 10 09:00 2012"})
+	if !reflect.DeepEqual(times, expected) {
+		t.Errorf("(expected) %v != %v (actual)", expected, times)
+	}
+}
+
+func getTimes(values []string) []time.Time {
+	var times []time.Time
+	for _, value := range values {
+		times = append(times, getTime(value))
+	}
+	return times
+}